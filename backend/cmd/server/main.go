@@ -13,6 +13,8 @@ import (
 	"0xdomainsnapshot/internal/collector/dns"
 	"0xdomainsnapshot/internal/config"
 	"0xdomainsnapshot/internal/database"
+	"0xdomainsnapshot/internal/logging"
+	"0xdomainsnapshot/internal/merger"
 	"0xdomainsnapshot/internal/scheduler"
 	"0xdomainsnapshot/internal/service"
 )
@@ -32,10 +34,23 @@ func main() {
 		log.Fatalf("Invalid configuration: %v", err)
 	}
 
+	if err := logging.Setup(cfg.Server.LogFormat); err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+
 	log.Printf("Configuration loaded successfully")
 	log.Printf("  Server: %s:%d", cfg.Server.Host, cfg.Server.Port)
 	log.Printf("  Static directory: %s", cfg.Server.StaticDir)
+	log.Printf("  Log format: %s", cfg.Server.LogFormat)
 	log.Printf("  Scheduler enabled: %v", cfg.Scheduler.Enabled)
+	if cfg.Scheduler.Jitter > 0 {
+		log.Printf("  Scheduler jitter: %v", cfg.Scheduler.Jitter)
+	}
+	log.Printf("  Minimum TLS for provider APIs: %s", cfg.HTTP.MinTLS)
+	if cfg.HTTP.InsecureSkipVerify {
+		log.Println("  WARNING: HTTP_INSECURE_SKIP_VERIFY is set - provider API certificates are NOT verified.")
+		log.Println("  WARNING: This is for test environments only; never enable it in production.")
+	}
 
 	// Connect to database
 	log.Println("Connecting to database...")
@@ -45,6 +60,9 @@ func main() {
 	}
 	defer db.Close()
 	log.Println("Database connected successfully")
+	if db.HasReadReplica() {
+		log.Println("Read replica connected for read-heavy queries")
+	}
 
 	// Run migrations
 	log.Println("Running database migrations...")
@@ -54,8 +72,15 @@ func main() {
 	}
 	log.Println("Migrations completed")
 
+	// Rewrite TXT data stored before it was normalized
+	if n, err := merger.New(db).NormalizeTXTRecords(ctx); err != nil {
+		log.Fatalf("Failed to normalize TXT records: %v", err)
+	} else if n > 0 {
+		log.Printf("Normalized %d stored TXT records", n)
+	}
+
 	// Create services
-	syncSvc := service.NewSyncService(db)
+	syncSvc := service.NewSyncService(db, cfg.Sync)
 	exportSvc := service.NewExportService(syncSvc, cfg.Export)
 	syncLock := scheduler.NewSyncLock(db)
 
@@ -64,19 +89,34 @@ func main() {
 
 	// Register DNS collectors
 	if cfg.GoDaddy.IsConfigured() {
-		gdCollector := dns.NewGoDaddyCollector(cfg.GoDaddy, cfg.RateLimit)
-		if err := registry.Register(gdCollector); err != nil {
+		gdCollector := dns.NewGoDaddyCollector(cfg.GoDaddy, cfg.RateLimit, cfg.HTTP).WithPTR(cfg.Sync.CollectPTR)
+		if err := registry.RegisterWithLabels(gdCollector, cfg.GoDaddy.Labels); err != nil {
 			log.Printf("Warning: Failed to register GoDaddy collector: %v", err)
 		} else {
 			log.Println("GoDaddy DNS collector registered")
 		}
-	} else {
+	} else if len(cfg.GoDaddy.AccountCredentials) == 0 {
 		log.Println("GoDaddy collector skipped (not configured)")
 	}
 
+	// One more GoDaddy collector per additional customer account
+	for _, acct := range cfg.GoDaddy.AccountCredentials {
+		labels := map[string]string{"account": acct.Name}
+		for k, v := range cfg.GoDaddy.Labels {
+			labels[k] = v
+		}
+
+		gdCollector := dns.NewGoDaddyCollector(cfg.GoDaddy.ForAccount(acct), cfg.RateLimit, cfg.HTTP).WithAccount(acct.Name).WithPTR(cfg.Sync.CollectPTR)
+		if err := registry.RegisterWithLabels(gdCollector, labels); err != nil {
+			log.Printf("Warning: Failed to register GoDaddy collector for account %s: %v", acct.Name, err)
+		} else {
+			log.Printf("GoDaddy DNS collector registered for account %s", acct.Name)
+		}
+	}
+
 	if cfg.Cloudflare.IsConfigured() {
-		cfCollector := dns.NewCloudflareCollector(cfg.Cloudflare, cfg.RateLimit)
-		if err := registry.Register(cfCollector); err != nil {
+		cfCollector := dns.NewCloudflareCollector(cfg.Cloudflare, cfg.RateLimit, cfg.HTTP).WithPTR(cfg.Sync.CollectPTR)
+		if err := registry.RegisterWithLabels(cfCollector, cfg.Cloudflare.Labels); err != nil {
 			log.Printf("Warning: Failed to register Cloudflare collector: %v", err)
 		} else {
 			log.Println("Cloudflare DNS collector registered")
@@ -85,13 +125,35 @@ func main() {
 		log.Println("Cloudflare collector skipped (not configured)")
 	}
 
+	if cfg.GitZones.IsConfigured() {
+		gzCollector := dns.NewGitZonesCollector(cfg.GitZones).WithPTR(cfg.Sync.CollectPTR)
+		if err := registry.RegisterWithLabels(gzCollector, cfg.GitZones.Labels); err != nil {
+			log.Printf("Warning: Failed to register Git zones collector: %v", err)
+		} else {
+			log.Println("Git zones DNS collector registered")
+		}
+	} else {
+		log.Println("Git zones collector skipped (not configured)")
+	}
+
+	if cfg.Namecheap.IsConfigured() {
+		ncCollector := dns.NewNamecheapCollector(cfg.Namecheap, cfg.RateLimit, cfg.HTTP).WithPTR(cfg.Sync.CollectPTR)
+		if err := registry.RegisterWithLabels(ncCollector, cfg.Namecheap.Labels); err != nil {
+			log.Printf("Warning: Failed to register Namecheap collector: %v", err)
+		} else {
+			log.Println("Namecheap DNS collector registered")
+		}
+	} else {
+		log.Println("Namecheap collector skipped (not configured)")
+	}
+
 	log.Printf("Registered %d collectors: %v", registry.Count(), registry.Names())
 
 	// Create scheduler
 	sched := scheduler.New(registry, syncSvc, exportSvc, syncLock, cfg.Scheduler)
 
 	// Create API server
-	server := api.NewServer(cfg.Server, sched, syncSvc, exportSvc)
+	server := api.NewServer(cfg, sched, syncSvc, exportSvc)
 
 	// Setup graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -108,6 +170,21 @@ func main() {
 		}
 	}()
 
+	// Don't silently serve an old export after an outage
+	if freshness := exportSvc.Freshness(); freshness.Stale {
+		if freshness.LastUpdated == nil {
+			log.Printf("WARNING: No published export found in %s", cfg.Export.OutputDir)
+		} else {
+			log.Printf("WARNING: Published export is stale (last updated %s ago, max %s)", freshness.Age, freshness.MaxAge)
+		}
+		if cfg.Export.SyncOnStaleStart {
+			log.Println("Triggering sync of all collectors (EXPORT_SYNC_ON_STALE_START)")
+			if err := sched.TriggerSyncAll(ctx); err != nil {
+				log.Printf("Warning: Failed to trigger sync: %v", err)
+			}
+		}
+	}
+
 	// Start server in background
 	serverErr := make(chan error, 1)
 	go func() {
@@ -125,15 +202,34 @@ func main() {
 	log.Printf("  API:       http://%s/api/v1/health", server.Addr())
 	log.Println("")
 	log.Println("Available API Endpoints:")
-	log.Println("  GET  /api/v1/health              - Health check")
+	log.Println("  GET  /api/v1/health              - Health check: database, collectors (deep=true adds export freshness)")
+	log.Println("  GET  /api/v1/openapi.json        - OpenAPI description of the API")
+	log.Println("  GET  /api/v1/config              - Effective configuration (secrets redacted)")
 	log.Println("  GET  /api/v1/sync/status         - All collector statuses")
 	log.Println("  GET  /api/v1/sync/status/{name}  - Single collector status")
-	log.Println("  POST /api/v1/sync/trigger/{name} - Trigger manual sync")
+	log.Println("  POST /api/v1/sync/trigger/{name} - Trigger manual sync (dry_run=true to preview)")
 	log.Println("  POST /api/v1/sync/trigger-all    - Trigger all syncs")
+	log.Println("  POST /api/v1/sync/cancel/{name}  - Cancel a running sync")
+	log.Println("  GET  /api/v1/sync/stats-history  - Daily sync statistics")
 	log.Println("  GET  /api/v1/domains             - Get domains")
+	log.Println("  GET  /api/v1/domains/empty       - Domains without DNS records")
+	log.Println("  GET  /api/v1/domains/{d}/records/merged - Effective record set across sources")
 	log.Println("  GET  /api/v1/dns-records         - Get DNS records")
+	log.Println("  GET  /api/v1/stats               - Asset counts per source and record type")
+	log.Println("  GET  /api/v1/ns-changes          - Nameserver delegation changes")
+	log.Println("  GET  /api/v1/txt-issues          - TXT length/SPF lookup issues")
+	log.Println("  GET  /api/v1/record-issues       - All record issues (TXT + invalid data)")
+	log.Println("  GET  /api/v1/domain-risks        - Domains with registrar lock or auto-renew off")
+	log.Println("  GET  /api/v1/alerts              - Everything needing attention, by severity")
+	log.Println("  GET  /api/v1/analysis/dangling   - CNAMEs pointing at deprovisioned services")
 	log.Println("  POST /api/v1/export              - Export JSON files")
+	log.Println("  GET  /api/v1/export/sqlite       - Download SQLite snapshot")
+	log.Println("  POST /api/v1/export/selective    - Export a list of domains")
+	log.Println("  GET  /api/v1/export/snapshots    - Archived exports")
+	log.Println("  POST /api/v1/export/from-snapshot/{id} - Republish an archived export")
 	log.Println("  GET  /api/v1/scheduler/jobs      - Scheduled jobs")
+	log.Println("  POST /api/v1/scheduler/pause     - Pause scheduled syncs")
+	log.Println("  POST /api/v1/scheduler/resume    - Resume scheduled syncs")
 	log.Println("")
 	log.Println("Press Ctrl+C to stop")
 	log.Println("")
@@ -0,0 +1,326 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"0xdomainsnapshot/internal/config"
+	"0xdomainsnapshot/internal/database"
+)
+
+// Reasons a CNAME record is reported
+const (
+	ReasonNXDOMAIN    = "nxdomain"    // Target has no addresses
+	ReasonFingerprint = "fingerprint" // Service answers with its unclaimed page
+	ReasonUnconfirmed = "unconfirmed" // Resolving service target, unclaimed page not seen (lower confidence)
+)
+
+// DanglingCNAME is a CNAME record whose target looks deprovisioned
+type DanglingCNAME struct {
+	CNAMERecord
+	Reason    string `json:"reason"`              // ReasonNXDOMAIN, ReasonFingerprint or ReasonUnconfirmed
+	Service   string `json:"service,omitempty"`   // Matched takeover fingerprint
+	Canonical string `json:"canonical,omitempty"` // Where the target's CNAME chain ends
+}
+
+// takeoverFingerprint is a third-party service whose resources can be
+// claimed by anyone once deprovisioned
+// Services whose hostnames keep resolving after the resource is gone have
+// an UnclaimedBody: the text (and UnclaimedStatus, when not 0) the service
+// serves for a hostname nobody has claimed. A resolving record pointing
+// there is only flagged once that page is seen; for the others only
+// NXDOMAIN is flagged.
+type takeoverFingerprint struct {
+	Service         string
+	Suffixes        []string
+	UnclaimedStatus int
+	UnclaimedBody   string
+}
+
+// takeoverFingerprints lists services with known subdomain takeovers
+var takeoverFingerprints = []takeoverFingerprint{
+	{Service: "AWS S3", Suffixes: []string{"s3.amazonaws.com", "s3-website.amazonaws.com"}},
+	{Service: "AWS Elastic Beanstalk", Suffixes: []string{"elasticbeanstalk.com"}},
+	{Service: "Azure", Suffixes: []string{"azurewebsites.net", "cloudapp.net", "cloudapp.azure.com", "trafficmanager.net", "blob.core.windows.net", "azureedge.net"}},
+	{Service: "GitHub Pages", Suffixes: []string{"github.io"}, UnclaimedStatus: http.StatusNotFound, UnclaimedBody: "There isn't a GitHub Pages site here."},
+	{Service: "Heroku", Suffixes: []string{"herokuapp.com", "herokudns.com"}, UnclaimedStatus: http.StatusNotFound, UnclaimedBody: "No such app"},
+	{Service: "Shopify", Suffixes: []string{"myshopify.com"}, UnclaimedBody: "Sorry, this shop is currently unavailable"},
+	{Service: "Fastly", Suffixes: []string{"fastly.net"}, UnclaimedBody: "Fastly error: unknown domain"},
+	{Service: "Netlify", Suffixes: []string{"netlify.app", "netlify.com"}},
+	{Service: "Pantheon", Suffixes: []string{"pantheonsite.io"}, UnclaimedStatus: http.StatusNotFound, UnclaimedBody: "The gods are wise"},
+	{Service: "Zendesk", Suffixes: []string{"zendesk.com"}, UnclaimedBody: "Help Center Closed"},
+	{Service: "Unbounce", Suffixes: []string{"unbouncepages.com"}, UnclaimedStatus: http.StatusNotFound, UnclaimedBody: "The requested URL was not found on this server"},
+}
+
+// maxProbeBody caps how much of a probed page is searched for the
+// unclaimed text
+const maxProbeBody = 64 << 10
+
+// matchFingerprint returns the fingerprint a hostname belongs to, nil if none
+func matchFingerprint(host string) *takeoverFingerprint {
+	host = normalizeHost(host)
+	for i, fp := range takeoverFingerprints {
+		for _, suffix := range fp.Suffixes {
+			if host == suffix || strings.HasSuffix(host, "."+suffix) {
+				return &takeoverFingerprints[i]
+			}
+		}
+	}
+	return nil
+}
+
+// unclaimed reports whether a response is the service's page for an
+// unclaimed hostname
+func (fp *takeoverFingerprint) unclaimed(status int, body string) bool {
+	if fp.UnclaimedBody == "" {
+		return false
+	}
+	if fp.UnclaimedStatus != 0 && status != fp.UnclaimedStatus {
+		return false
+	}
+	return strings.Contains(body, fp.UnclaimedBody)
+}
+
+// lookup is the outcome of resolving one CNAME target
+type lookup struct {
+	nxdomain  bool
+	canonical string
+	err       error // Lookup failed for another reason (timeout, SERVFAIL)
+}
+
+// FindDanglingCNAMEs resolves the targets of the takeover candidates (see
+// TakeoverCandidates) and returns the records whose target does not exist
+// (NXDOMAIN) or points at a service with a known takeover fingerprint
+// A resolving record on such a service is fetched over HTTP and reported
+// as ReasonFingerprint only when the service answers with its unclaimed
+// page; otherwise it is reported as ReasonUnconfirmed.
+// Each distinct target is looked up once, with at most ResolverConcurrency
+// lookups (and then probes) in flight. Targets that fail to resolve for
+// other reasons are not flagged, only counted in the log.
+func FindDanglingCNAMEs(ctx context.Context, db *database.DB, cfg config.AnalysisConfig) ([]DanglingCNAME, error) {
+	candidates, err := TakeoverCandidates(ctx, db, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string]*lookup)
+	for _, r := range candidates {
+		targets[normalizeHost(r.Target)] = nil
+	}
+
+	resolveTargets(ctx, newResolver(cfg.Resolver), cfg, targets)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var dangling []DanglingCNAME
+	var probes []*probe
+	var failed int
+	for _, r := range candidates {
+		res := targets[normalizeHost(r.Target)]
+		if res.err != nil {
+			failed++
+			continue
+		}
+
+		d := DanglingCNAME{CNAMERecord: r, Canonical: res.canonical}
+		fp := matchFingerprint(r.Target)
+		if fp == nil && res.canonical != "" {
+			fp = matchFingerprint(res.canonical)
+		}
+		if fp != nil {
+			d.Service = fp.Service
+		}
+
+		if !res.nxdomain && fp != nil && fp.UnclaimedBody != "" {
+			probes = append(probes, &probe{index: len(dangling), host: r.FQDN, fp: fp})
+		}
+		if d.Reason = classify(res, fp, false); d.Reason == "" {
+			continue
+		}
+		dangling = append(dangling, d)
+	}
+
+	probeUnclaimed(ctx, cfg, probes)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var confirmed int
+	for _, p := range probes {
+		if p.unclaimed {
+			dangling[p.index].Reason = classify(targets[normalizeHost(dangling[p.index].Target)], p.fp, true)
+			confirmed++
+		}
+	}
+
+	log.Printf("[Analysis] Dangling CNAME check: %d candidates, %d targets, %d reported (%d unclaimed pages confirmed of %d probed), %d lookups failed",
+		len(candidates), len(targets), len(dangling), confirmed, len(probes), failed)
+
+	return dangling, nil
+}
+
+// classify returns the reason a record is reported, "" if it is not
+// unclaimed tells whether the service served its unclaimed page for the
+// record's hostname.
+func classify(res *lookup, fp *takeoverFingerprint, unclaimed bool) string {
+	switch {
+	case res.nxdomain:
+		return ReasonNXDOMAIN
+	case fp == nil || fp.UnclaimedBody == "":
+		return ""
+	case unclaimed:
+		return ReasonFingerprint
+	default:
+		return ReasonUnconfirmed
+	}
+}
+
+// probe is a pending check of a record's hostname for its service's
+// unclaimed page
+type probe struct {
+	index     int    // Position in the reported records
+	host      string // Record FQDN, the name the service routes on
+	fp        *takeoverFingerprint
+	unclaimed bool
+}
+
+// probeUnclaimed fetches each probed hostname over HTTP, at most
+// ResolverConcurrency at a time, and records whether the service answered
+// with its unclaimed page. Failed fetches leave the record unconfirmed.
+func probeUnclaimed(ctx context.Context, cfg config.AnalysisConfig, probes []*probe) {
+	client := &http.Client{Timeout: cfg.ResolverTimeout}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(cfg.ResolverConcurrency, 1))
+
+	for _, p := range probes {
+		// A wildcard name can't be fetched
+		if strings.Contains(p.host, "*") {
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+
+		wg.Add(1)
+		go func(p *probe) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			p.unclaimed = fetchUnclaimed(ctx, client, "http://"+p.host+"/", p.host, p.fp)
+		}(p)
+	}
+
+	wg.Wait()
+}
+
+// fetchUnclaimed requests url with the given Host header and reports
+// whether the response is fp's unclaimed page
+func fetchUnclaimed(ctx context.Context, client *http.Client, url, host string, fp *takeoverFingerprint) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+	req.Host = host
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBody))
+	if err != nil {
+		return false
+	}
+	return fp.unclaimed(resp.StatusCode, string(body))
+}
+
+// resolveTargets fills in the lookup result of every target
+func resolveTargets(ctx context.Context, resolver *net.Resolver, cfg config.AnalysisConfig, targets map[string]*lookup) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(cfg.ResolverConcurrency, 1))
+
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+
+	for _, name := range names {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			res := resolveTarget(ctx, resolver, cfg.ResolverTimeout, name)
+
+			mu.Lock()
+			targets[name] = res
+			mu.Unlock()
+		}(name)
+	}
+
+	wg.Wait()
+}
+
+// resolveTarget follows a target's CNAME chain and checks that it exists
+func resolveTarget(ctx context.Context, resolver *net.Resolver, timeout time.Duration, name string) *lookup {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	res := &lookup{}
+	if _, err := resolver.LookupHost(ctx, name); err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			res.nxdomain = true
+			return res
+		}
+		res.err = err
+		return res
+	}
+
+	if canonical, err := resolver.LookupCNAME(ctx, name); err == nil {
+		if canonical = normalizeHost(canonical); canonical != name {
+			res.canonical = canonical
+		}
+	}
+	return res
+}
+
+// newResolver returns a resolver querying the given server (host:port), or
+// the system resolver when empty
+func newResolver(server string) *net.Resolver {
+	if server == "" {
+		return net.DefaultResolver
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
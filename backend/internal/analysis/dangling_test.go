@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMatchFingerprint(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"acme.github.io", "GitHub Pages"},
+		{"ACME.GitHub.io.", "GitHub Pages"},
+		{"github.io", "GitHub Pages"},
+		{"shop.myshopify.com", "Shopify"},
+		{"bucket.s3.amazonaws.com", "AWS S3"},
+		{"app.azurewebsites.net", "Azure"},
+		{"notgithub.io", ""},
+		{"github.io.acme-corp.net", ""},
+		{"www.acme-corp.net", ""},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if fp := matchFingerprint(tt.host); fp != nil {
+			got = fp.Service
+		}
+		if got != tt.want {
+			t.Errorf("matchFingerprint(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	githubPages := matchFingerprint("acme.github.io")
+	s3 := matchFingerprint("bucket.s3.amazonaws.com")
+
+	tests := []struct {
+		name      string
+		res       *lookup
+		fp        *takeoverFingerprint
+		unclaimed bool
+		want      string
+	}{
+		{"nxdomain without fingerprint", &lookup{nxdomain: true}, nil, false, ReasonNXDOMAIN},
+		{"nxdomain with fingerprint", &lookup{nxdomain: true}, s3, false, ReasonNXDOMAIN},
+		{"resolving without fingerprint", &lookup{}, nil, false, ""},
+		{"resolving, service without unclaimed page", &lookup{}, s3, true, ""},
+		{"resolving, unclaimed page seen", &lookup{}, githubPages, true, ReasonFingerprint},
+		{"resolving, unclaimed page not seen", &lookup{}, githubPages, false, ReasonUnconfirmed},
+	}
+
+	for _, tt := range tests {
+		if got := classify(tt.res, tt.fp, tt.unclaimed); got != tt.want {
+			t.Errorf("%s: classify = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFetchUnclaimed(t *testing.T) {
+	githubPages := matchFingerprint("acme.github.io")
+	shopify := matchFingerprint("shop.myshopify.com")
+
+	var gotHost string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost = r.Host
+		switch r.Host {
+		case "docs.acme-corp.net":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("<h1>404</h1><p>There isn't a GitHub Pages site here.</p>"))
+		case "moved.acme-corp.net":
+			// Unclaimed text, but not with the status the service uses
+			w.Write([]byte("There isn't a GitHub Pages site here."))
+		case "shop.acme-corp.net":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("Sorry, this shop is currently unavailable."))
+		default:
+			w.Write([]byte("Welcome"))
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		host string
+		fp   *takeoverFingerprint
+		want bool
+	}{
+		{"docs.acme-corp.net", githubPages, true},
+		{"moved.acme-corp.net", githubPages, false},
+		{"www.acme-corp.net", githubPages, false},
+		{"shop.acme-corp.net", shopify, true},
+	}
+
+	for _, tt := range tests {
+		if got := fetchUnclaimed(context.Background(), srv.Client(), srv.URL, tt.host, tt.fp); got != tt.want {
+			t.Errorf("fetchUnclaimed(%q, %s) = %v, want %v", tt.host, tt.fp.Service, got, tt.want)
+		}
+		if gotHost != tt.host {
+			t.Errorf("request Host = %q, want %q", gotHost, tt.host)
+		}
+	}
+
+	srv.Close()
+	if fetchUnclaimed(context.Background(), srv.Client(), srv.URL, "docs.acme-corp.net", githubPages) {
+		t.Error("fetchUnclaimed on a closed server = true, want false")
+	}
+}
@@ -0,0 +1,133 @@
+package analysis
+
+import (
+	"context"
+	"strings"
+
+	"0xdomainsnapshot/internal/collector/dns"
+	"0xdomainsnapshot/internal/config"
+	"0xdomainsnapshot/internal/database"
+)
+
+// CNAMERecord is an active CNAME record considered for takeover analysis
+type CNAMERecord struct {
+	Domain    string `json:"domain"`
+	Subdomain string `json:"subdomain"`
+	FQDN      string `json:"fqdn"`
+	Target    string `json:"target"`
+	Source    string `json:"source"`
+}
+
+// TakeoverFilter decides which CNAME records are worth analyzing
+// A record pointing at something we own can't be claimed by a third party,
+// and wildcard records mostly produce noise.
+type TakeoverFilter struct {
+	ownedSuffixes []string
+	skipWildcards bool
+	inventory     map[string]bool // Our domains
+}
+
+// NewTakeoverFilter creates a filter from the analysis config and the
+// names of the domains in our inventory
+func NewTakeoverFilter(cfg config.AnalysisConfig, domains []string) *TakeoverFilter {
+	f := &TakeoverFilter{
+		skipWildcards: cfg.SkipWildcards,
+		inventory:     make(map[string]bool, len(domains)),
+	}
+	for _, suffix := range cfg.OwnedSuffixes {
+		if suffix = normalizeHost(suffix); suffix != "" {
+			f.ownedSuffixes = append(f.ownedSuffixes, suffix)
+		}
+	}
+	for _, d := range domains {
+		f.inventory[normalizeHost(d)] = true
+	}
+	return f
+}
+
+// Excluded returns why a record is not a takeover candidate, "" if it is
+func (f *TakeoverFilter) Excluded(r CNAMERecord) string {
+	if f.skipWildcards && (r.Subdomain == "*" || strings.HasPrefix(r.Subdomain, "*.")) {
+		return "wildcard record"
+	}
+
+	target := normalizeHost(r.Target)
+	for _, suffix := range f.ownedSuffixes {
+		if target == suffix || strings.HasSuffix(target, "."+suffix) {
+			return "target under owned suffix " + suffix
+		}
+	}
+
+	// The target or one of its parents is a domain in our inventory
+	for name := target; name != ""; {
+		if f.inventory[name] {
+			return "target in inventory (" + name + ")"
+		}
+		_, parent, found := strings.Cut(name, ".")
+		if !found {
+			break
+		}
+		name = parent
+	}
+
+	return ""
+}
+
+// TakeoverCandidates returns the active CNAME records the filter keeps
+func TakeoverCandidates(ctx context.Context, db *database.DB, cfg config.AnalysisConfig) ([]CNAMERecord, error) {
+	domains, err := inventoryDomains(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	filter := NewTakeoverFilter(cfg, domains)
+
+	rows, err := db.Reader().QueryContext(ctx, `
+		SELECT domain, subdomain, data, source
+		FROM dns_records
+		WHERE status = 'active' AND record_type = 'CNAME'
+		ORDER BY domain, subdomain, source
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []CNAMERecord
+	for rows.Next() {
+		var r CNAMERecord
+		if err := rows.Scan(&r.Domain, &r.Subdomain, &r.Target, &r.Source); err != nil {
+			return nil, err
+		}
+		r.FQDN = dns.FQDN(r.Subdomain, r.Domain)
+
+		if filter.Excluded(r) == "" {
+			candidates = append(candidates, r)
+		}
+	}
+
+	return candidates, rows.Err()
+}
+
+// inventoryDomains returns the names of our active domains
+func inventoryDomains(ctx context.Context, db *database.DB) ([]string, error) {
+	rows, err := db.Reader().QueryContext(ctx, `SELECT DISTINCT domain FROM domains WHERE status = 'active'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var domains []string
+	for rows.Next() {
+		var d string
+		if err := rows.Scan(&d); err != nil {
+			return nil, err
+		}
+		domains = append(domains, d)
+	}
+	return domains, rows.Err()
+}
+
+// normalizeHost lowercases a hostname and strips surrounding dots
+func normalizeHost(host string) string {
+	return strings.Trim(strings.ToLower(strings.TrimSpace(host)), ".")
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Alert severities, most urgent first
+const (
+	severityCritical = "critical"
+	severityWarning  = "warning"
+	severityInfo     = "info"
+)
+
+var severityRank = map[string]int{
+	severityCritical: 0,
+	severityWarning:  1,
+	severityInfo:     2,
+}
+
+// expiryCriticalWindow is when an expiring domain becomes critical; the
+// warning window is SCHEDULER_EXPIRY_WARNING
+const expiryCriticalWindow = 7 * 24 * time.Hour
+
+// Alert is one problem that needs an operator's attention
+type Alert struct {
+	Severity string `json:"severity"`
+	Category string `json:"category"`          // collector, export, expiry, domain_risk, record_issues, takeover
+	Subject  string `json:"subject,omitempty"` // Collector or domain the alert is about
+	Message  string `json:"message"`
+	Count    int    `json:"count,omitempty"` // Affected items, for summarized alerts
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// collectAlerts composes the current alerts from the sync status, the
+// export freshness and the analyzers, ordered by severity
+// Per-item alerts are raised for failing collectors, a stale export and
+// expiring domains; record issues, domain risks and takeover candidates are
+// summarized with a count and the endpoint listing them.
+func (s *Server) collectAlerts(ctx context.Context) ([]Alert, error) {
+	var alerts []Alert
+
+	// Failing collectors
+	statuses, err := s.scheduler.GetAllStatus(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("sync status: %w", err)
+	}
+	for _, st := range statuses {
+		if st.Status != "failed" {
+			continue
+		}
+		alerts = append(alerts, Alert{
+			Severity: severityCritical,
+			Category: "collector",
+			Subject:  st.Name,
+			Message:  fmt.Sprintf("last sync failed at %s: %s", st.StartedAt.UTC().Format(time.RFC3339), st.ErrorMessage),
+			Endpoint: "/api/v1/sync/status/" + st.Name,
+		})
+	}
+
+	// Stale export
+	if export := s.exportSvc.Freshness(); export.Stale {
+		message := "no export has been published"
+		if export.LastUpdated != nil {
+			message = fmt.Sprintf("published export is %s old (max %s)", export.Age, export.MaxAge)
+		}
+		alerts = append(alerts, Alert{
+			Severity: severityWarning,
+			Category: "export",
+			Message:  message,
+			Endpoint: "/api/v1/health?deep=true",
+		})
+	}
+
+	// Expiring domains
+	window := s.appCfg.Scheduler.ExpiryWarning
+	expiring, err := s.syncSvc.GetExpiringDomains(ctx, window)
+	if err != nil {
+		return nil, fmt.Errorf("expiring domains: %w", err)
+	}
+	urgent, err := s.syncSvc.GetExpiringDomains(ctx, expiryCriticalWindow)
+	if err != nil {
+		return nil, fmt.Errorf("expiring domains: %w", err)
+	}
+	isUrgent := make(map[string]bool, len(urgent))
+	for _, d := range urgent {
+		isUrgent[fmt.Sprint(d["domain"])] = true
+	}
+	for _, d := range expiring {
+		domain := fmt.Sprint(d["domain"])
+		severity := severityWarning
+		if isUrgent[domain] {
+			severity = severityCritical
+		}
+		alerts = append(alerts, Alert{
+			Severity: severity,
+			Category: "expiry",
+			Subject:  domain,
+			Message:  fmt.Sprintf("expires %v (%v)", d["expiry_date"], d["registrar"]),
+			Endpoint: fmt.Sprintf("/api/v1/domains?expiring_within=%dh", int(window.Hours())),
+		})
+	}
+
+	// Summarized analyzer results
+	risks, err := s.syncSvc.GetDomainRisks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("domain risks: %w", err)
+	}
+	if len(risks) > 0 {
+		alerts = append(alerts, Alert{
+			Severity: severityWarning,
+			Category: "domain_risk",
+			Message:  "domains with registrar lock or auto-renew disabled",
+			Count:    len(risks),
+			Endpoint: "/api/v1/domain-risks",
+		})
+	}
+
+	issues, err := s.syncSvc.GetRecordIssues(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("record issues: %w", err)
+	}
+	if len(issues) > 0 {
+		alerts = append(alerts, Alert{
+			Severity: severityInfo,
+			Category: "record_issues",
+			Message:  "active DNS records with known problems",
+			Count:    len(issues),
+			Endpoint: "/api/v1/record-issues",
+		})
+	}
+
+	candidates, err := s.syncSvc.GetTakeoverCandidates(ctx, s.appCfg.Analysis)
+	if err != nil {
+		return nil, fmt.Errorf("takeover candidates: %w", err)
+	}
+	if len(candidates) > 0 {
+		alerts = append(alerts, Alert{
+			Severity: severityInfo,
+			Category: "takeover",
+			Message:  "CNAME records pointing outside our inventory and owned suffixes (takeover candidates)",
+			Count:    len(candidates),
+		})
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return severityRank[alerts[i].Severity] < severityRank[alerts[j].Severity]
+	})
+
+	return alerts, nil
+}
+
+// handleAlerts handles GET /api/v1/alerts
+func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	alerts, err := s.collectAlerts(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if alerts == nil {
+		alerts = []Alert{}
+	}
+
+	counts := map[string]int{severityCritical: 0, severityWarning: 0, severityInfo: 0}
+	for _, a := range alerts {
+		counts[a.Severity]++
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"alerts": alerts,
+		"counts": counts,
+	})
+}
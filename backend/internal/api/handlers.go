@@ -1,10 +1,21 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+
+	"0xdomainsnapshot/internal/analysis"
+	"0xdomainsnapshot/internal/scheduler"
+	"0xdomainsnapshot/internal/service"
 )
 
 // Response helpers
@@ -21,12 +32,135 @@ func respondError(w http.ResponseWriter, status int, message string) {
 	respondJSON(w, status, map[string]string{"error": message})
 }
 
+// parseLabels parses repeated ?label=key:value query parameters
+func parseLabels(r *http.Request) (map[string]string, error) {
+	values := r.URL.Query()["label"]
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	labels := make(map[string]string, len(values))
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, ":")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid label %q, expected key:value", v)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// decodeJSON strictly decodes a JSON request body into v
+// Unknown fields are rejected. On failure it writes a 413 (body too large)
+// or 400 response and returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			respondError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return false
+		}
+		respondError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		return false
+	}
+
+	// Reject trailing data after the JSON value
+	if decoder.More() {
+		respondError(w, http.StatusBadRequest, "invalid JSON body: unexpected trailing data")
+		return false
+	}
+
+	return true
+}
+
 // Health check
 
+// healthPingTimeout bounds the database ping of a health check
+const healthPingTimeout = 2 * time.Second
+
+// healthCheck is the outcome of one health check
+type healthCheck struct {
+	Status string `json:"status"` // "ok" or "error"
+	Error  string `json:"error,omitempty"`
+}
+
+func newHealthCheck(err error) healthCheck {
+	if err != nil {
+		return healthCheck{Status: "error", Error: err.Error()}
+	}
+	return healthCheck{Status: "ok"}
+}
+
 // handleHealth handles GET /api/v1/health
+// Pings the database and reports each collector's configuration check.
+// The status is "unhealthy" (503) when the database can't be reached and
+// "degraded" when a collector is misconfigured. With deep=true the
+// published export's freshness is included too, and a stale export is
+// also "degraded".
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	respondJSON(w, http.StatusOK, map[string]string{
-		"status": "healthy",
+	status := "healthy"
+
+	ctx, cancel := context.WithTimeout(r.Context(), healthPingTimeout)
+	defer cancel()
+	database := newHealthCheck(s.syncSvc.Ping(ctx))
+
+	collectors := make(map[string]healthCheck)
+	for name, err := range s.scheduler.ValidateCollectors() {
+		collectors[name] = newHealthCheck(err)
+		if err != nil {
+			status = "degraded"
+		}
+	}
+
+	checks := map[string]interface{}{
+		"database":   database,
+		"collectors": collectors,
+	}
+
+	if deep, _ := strconv.ParseBool(r.URL.Query().Get("deep")); deep {
+		export := s.exportSvc.Freshness()
+		if export.Stale {
+			status = "degraded"
+		}
+		checks["export"] = export
+	}
+
+	code := http.StatusOK
+	if database.Status != "ok" {
+		status = "unhealthy"
+		code = http.StatusServiceUnavailable
+	}
+
+	respondJSON(w, code, map[string]interface{}{
+		"status": status,
+		"checks": checks,
+	})
+}
+
+// handleConfig handles GET /api/v1/config
+// Returns the effective configuration with secrets redacted, plus which
+// providers are configured and the scheduled jobs, for support requests.
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	jobs := s.scheduler.GetScheduledJobs()
+	if jobs == nil {
+		jobs = []scheduler.ScheduledJobInfo{}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"config": s.appCfg.Redacted(),
+		"providers": map[string]bool{
+			"godaddy":    s.appCfg.GoDaddy.IsConfigured() || len(s.appCfg.GoDaddy.AccountCredentials) > 0,
+			"cloudflare": s.appCfg.Cloudflare.IsConfigured(),
+			"gitzones":   s.appCfg.GitZones.IsConfigured(),
+			"namecheap":  s.appCfg.Namecheap.IsConfigured(),
+		},
+		"scheduler": map[string]interface{}{
+			"paused": s.scheduler.IsPaused(),
+			"jobs":   jobs,
+		},
 	})
 }
 
@@ -34,14 +168,20 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 // handleSyncStatus handles GET /api/v1/sync/status
 func (s *Server) handleSyncStatus(w http.ResponseWriter, r *http.Request) {
-	statuses, err := s.scheduler.GetAllStatus(r.Context())
+	labels, err := parseLabels(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	statuses, err := s.scheduler.GetAllStatus(r.Context(), labels)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	if statuses == nil {
-		statuses = []interface{}{}
+		statuses = []scheduler.CollectorStatusInfo{}
 	}
 
 	respondJSON(w, http.StatusOK, map[string]interface{}{
@@ -95,6 +235,12 @@ func (s *Server) handleTriggerSync(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Dry run: collect and report what would change, synchronously
+	if dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run")); dryRun {
+		s.handleDryRun(w, r, collectorName)
+		return
+	}
+
 	// Check if already running
 	running, err := s.scheduler.IsCollectorRunning(r.Context(), collectorName)
 	if err != nil {
@@ -111,8 +257,13 @@ func (s *Server) handleTriggerSync(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Trigger sync
-	err = s.scheduler.TriggerSync(r.Context(), collectorName)
+	// Trigger sync; confirm_drop applies removals the record drop guard held
+	// back. The run outlives the request (stop it with /sync/cancel).
+	ctx := context.WithoutCancel(r.Context())
+	if confirm, _ := strconv.ParseBool(r.URL.Query().Get("confirm_drop")); confirm {
+		ctx = service.WithDropConfirmed(ctx)
+	}
+	err = s.scheduler.TriggerSync(ctx, collectorName)
 	if err != nil {
 		respondError(w, http.StatusBadRequest, err.Error())
 		return
@@ -125,9 +276,39 @@ func (s *Server) handleTriggerSync(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleDryRun handles POST /api/v1/sync/trigger/{collector}?dry_run=true
+// A record drop beyond SYNC_MAX_DROP_PERCENT is returned as a warning with
+// the stats rather than as an error.
+func (s *Server) handleDryRun(w http.ResponseWriter, r *http.Request, collectorName string) {
+	stats, err := s.scheduler.DryRun(r.Context(), collectorName)
+	if errors.Is(err, scheduler.ErrCollectorNotFound) {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var warning string
+	if errors.Is(err, service.ErrRecordDrop) {
+		warning = err.Error()
+	} else if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":    "dry_run",
+		"collector": collectorName,
+		"found":     stats.Found,
+		"added":     stats.Added,
+		"updated":   stats.Updated,
+		"removed":   stats.Removed,
+		"warning":   warning,
+	})
+}
+
 // handleTriggerSyncAll handles POST /api/v1/sync/trigger-all
 func (s *Server) handleTriggerSyncAll(w http.ResponseWriter, r *http.Request) {
-	err := s.scheduler.TriggerSyncAll(r.Context())
+	// The runs outlive the request
+	err := s.scheduler.TriggerSyncAll(context.WithoutCancel(r.Context()))
 	if err != nil {
 		respondError(w, http.StatusBadRequest, err.Error())
 		return
@@ -139,6 +320,63 @@ func (s *Server) handleTriggerSyncAll(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleCancelSync handles POST /api/v1/sync/cancel/{collector}
+func (s *Server) handleCancelSync(w http.ResponseWriter, r *http.Request) {
+	collectorName := chi.URLParam(r, "collector")
+	if collectorName == "" {
+		respondError(w, http.StatusBadRequest, "collector name required")
+		return
+	}
+
+	if err := s.scheduler.CancelSync(collectorName); err != nil {
+		if errors.Is(err, scheduler.ErrNotRunning) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, map[string]interface{}{
+		"status":    "cancelling",
+		"collector": collectorName,
+		"message":   "Sync cancellation requested",
+	})
+}
+
+// handleStatsHistory handles GET /api/v1/sync/stats-history
+func (s *Server) handleStatsHistory(w http.ResponseWriter, r *http.Request) {
+	collectorName := r.URL.Query().Get("collector")
+
+	days := 30
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 365 {
+			respondError(w, http.StatusBadRequest, "days must be between 1 and 365")
+			return
+		}
+		days = n
+	}
+
+	labels, err := parseLabels(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	history, err := s.scheduler.GetStatsHistory(r.Context(), collectorName, days, labels)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"collector": collectorName,
+		"days":      days,
+		"history":   history,
+	})
+}
+
 // Data endpoints
 
 // handleGetDomains handles GET /api/v1/domains
@@ -147,7 +385,113 @@ func (s *Server) handleGetDomains(w http.ResponseWriter, r *http.Request) {
 	status := r.URL.Query().Get("status")
 	source := r.URL.Query().Get("source")
 
-	domains, err := s.syncSvc.GetDomains(r.Context(), status, source)
+	var within time.Duration
+	if v := r.URL.Query().Get("expiring_within"); v != "" {
+		d, err := parseWindow(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "expiring_within must be a positive duration like 30d or 72h")
+			return
+		}
+		within = d
+	}
+
+	limit, offset, err := parsePage(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q := service.DomainQuery{
+		Status:         status,
+		Source:         source,
+		ExpiringWithin: within,
+		Limit:          limit,
+		Offset:         offset,
+	}
+	domains, err := s.syncSvc.GetDomains(r.Context(), q)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if domains == nil {
+		domains = []map[string]interface{}{}
+	}
+
+	// limit=0 returns the bare list, as before pagination
+	if limit == 0 {
+		respondJSON(w, http.StatusOK, domains)
+		return
+	}
+
+	total, err := s.syncSvc.CountDomains(r.Context(), q)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, page{Items: domains, Total: total, Limit: limit, Offset: offset})
+}
+
+// Page size limits for list endpoints
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+// page is the paginated response of list endpoints
+type page struct {
+	Items  []map[string]interface{} `json:"items"`
+	Total  int                      `json:"total"`
+	Limit  int                      `json:"limit"`
+	Offset int                      `json:"offset"`
+}
+
+// parsePage parses the limit and offset query parameters
+// limit defaults to 100 and is capped at 1000; limit=0 disables paging.
+func parsePage(r *http.Request) (limit, offset int, err error) {
+	limit = defaultPageLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 0 {
+			return 0, 0, fmt.Errorf("limit must be a non-negative integer")
+		}
+		if limit > maxPageLimit {
+			limit = maxPageLimit
+		}
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+	}
+	return limit, offset, nil
+}
+
+// parseWindow parses a positive duration, also accepting whole days ("30d")
+func parseWindow(v string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(v); err != nil {
+			return 0, err
+		}
+	}
+
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive")
+	}
+	return d, nil
+}
+
+// handleGetEmptyDomains handles GET /api/v1/domains/empty
+func (s *Server) handleGetEmptyDomains(w http.ResponseWriter, r *http.Request) {
+	domains, err := s.syncSvc.GetDomainsWithoutRecords(r.Context())
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -160,14 +504,73 @@ func (s *Server) handleGetDomains(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, domains)
 }
 
+// handleGetMergedRecords handles GET /api/v1/domains/{domain}/records/merged
+func (s *Server) handleGetMergedRecords(w http.ResponseWriter, r *http.Request) {
+	domain := strings.ToLower(chi.URLParam(r, "domain"))
+
+	view, err := s.syncSvc.GetMergedRecords(r.Context(), domain)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, view)
+}
+
 // handleGetDNSRecords handles GET /api/v1/dns-records
 func (s *Server) handleGetDNSRecords(w http.ResponseWriter, r *http.Request) {
 	// Query parameters
 	status := r.URL.Query().Get("status")
 	source := r.URL.Query().Get("source")
 	domain := r.URL.Query().Get("domain")
+	pattern := r.URL.Query().Get("pattern")
+
+	// type=A,AAAA filters on several record types
+	var types []string
+	for _, t := range strings.Split(r.URL.Query().Get("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
 
-	records, err := s.syncSvc.GetDNSRecords(r.Context(), status, source, domain)
+	var proxied *bool
+	if v := r.URL.Query().Get("proxied"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "proxied must be true or false")
+			return
+		}
+		proxied = &b
+	}
+
+	consolidate := false
+	if v := r.URL.Query().Get("consolidate"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "consolidate must be true or false")
+			return
+		}
+		consolidate = b
+	}
+
+	limit, offset, err := parsePage(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q := service.DNSRecordQuery{
+		Status:      status,
+		Source:      source,
+		Domain:      domain,
+		Proxied:     proxied,
+		Types:       types,
+		Pattern:     pattern,
+		Consolidate: consolidate,
+		Limit:       limit,
+		Offset:      offset,
+	}
+	records, err := s.syncSvc.GetDNSRecords(r.Context(), q)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -177,7 +580,116 @@ func (s *Server) handleGetDNSRecords(w http.ResponseWriter, r *http.Request) {
 		records = []map[string]interface{}{}
 	}
 
-	respondJSON(w, http.StatusOK, records)
+	// limit=0 returns the bare list, as before pagination
+	if limit == 0 {
+		respondJSON(w, http.StatusOK, records)
+		return
+	}
+
+	total, err := s.syncSvc.CountDNSRecords(r.Context(), q)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, page{Items: records, Total: total, Limit: limit, Offset: offset})
+}
+
+// handleNSChanges handles GET /api/v1/ns-changes
+func (s *Server) handleNSChanges(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			respondError(w, http.StatusBadRequest, "days must be a positive integer")
+			return
+		}
+		days = n
+	}
+
+	changes, err := s.syncSvc.GetNSChanges(r.Context(), days)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if changes == nil {
+		changes = []service.NSChange{}
+	}
+
+	respondJSON(w, http.StatusOK, changes)
+}
+
+// handleTXTIssues handles GET /api/v1/txt-issues
+func (s *Server) handleTXTIssues(w http.ResponseWriter, r *http.Request) {
+	issues, err := s.syncSvc.GetTXTIssues(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if issues == nil {
+		issues = []service.RecordIssue{}
+	}
+
+	respondJSON(w, http.StatusOK, issues)
+}
+
+// handleRecordIssues handles GET /api/v1/record-issues
+func (s *Server) handleRecordIssues(w http.ResponseWriter, r *http.Request) {
+	issues, err := s.syncSvc.GetRecordIssues(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if issues == nil {
+		issues = []service.RecordIssue{}
+	}
+
+	respondJSON(w, http.StatusOK, issues)
+}
+
+// handleStats handles GET /api/v1/stats
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.syncSvc.GetStats(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, stats)
+}
+
+// handleDomainRisks handles GET /api/v1/domain-risks
+func (s *Server) handleDomainRisks(w http.ResponseWriter, r *http.Request) {
+	risks, err := s.syncSvc.GetDomainRisks(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if risks == nil {
+		risks = []service.DomainRisk{}
+	}
+
+	respondJSON(w, http.StatusOK, risks)
+}
+
+// handleDanglingCNAMEs handles GET /api/v1/analysis/dangling
+// Resolves every candidate target, so it can take a while on large inventories.
+func (s *Server) handleDanglingCNAMEs(w http.ResponseWriter, r *http.Request) {
+	dangling, err := s.syncSvc.GetDanglingCNAMEs(r.Context(), s.appCfg.Analysis)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if dangling == nil {
+		dangling = []analysis.DanglingCNAME{}
+	}
+
+	respondJSON(w, http.StatusOK, dangling)
 }
 
 // Export endpoint
@@ -196,6 +708,130 @@ func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleExportSnapshots handles GET /api/v1/export/snapshots
+func (s *Server) handleExportSnapshots(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := s.exportSvc.ListSnapshots()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if snapshots == nil {
+		snapshots = []service.ExportSnapshot{}
+	}
+
+	respondJSON(w, http.StatusOK, snapshots)
+}
+
+// handleExportFromSnapshot handles POST /api/v1/export/from-snapshot/{id}
+func (s *Server) handleExportFromSnapshot(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := s.exportSvc.ExportFromSnapshot(chi.URLParam(r, "id"))
+	if errors.Is(err, service.ErrSnapshotNotFound) {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":   "success",
+		"message":  "Snapshot republished",
+		"snapshot": snapshot,
+	})
+}
+
+// handleExportSQLite handles GET /api/v1/export/sqlite
+func (s *Server) handleExportSQLite(w http.ResponseWriter, r *http.Request) {
+	tmp, err := os.CreateTemp("", "snapshot-*.db")
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	path := tmp.Name()
+	tmp.Close()
+	defer os.Remove(path)
+
+	if err := s.exportSvc.ExportSQLite(r.Context(), path); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	w.Header().Set("Content-Disposition", `attachment; filename="snapshot.db"`)
+	http.ServeContent(w, r, "snapshot.db", info.ModTime(), f)
+}
+
+// selectiveExportRequest is the body of POST /api/v1/export/selective
+type selectiveExportRequest struct {
+	Domains []string `json:"domains"`
+	Format  string   `json:"format"` // "json" (default) or "csv"
+}
+
+// maxSelectiveDomains caps the number of domains in a selective export
+const maxSelectiveDomains = 1000
+
+// handleExportSelective handles POST /api/v1/export/selective
+func (s *Server) handleExportSelective(w http.ResponseWriter, r *http.Request) {
+	var req selectiveExportRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if len(req.Domains) == 0 {
+		respondError(w, http.StatusBadRequest, "domains is required")
+		return
+	}
+	if len(req.Domains) > maxSelectiveDomains {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("at most %d domains per export", maxSelectiveDomains))
+		return
+	}
+
+	for i, d := range req.Domains {
+		req.Domains[i] = strings.ToLower(strings.TrimSpace(d))
+	}
+
+	format := strings.ToLower(req.Format)
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		respondError(w, http.StatusBadRequest, "format must be json or csv")
+		return
+	}
+
+	export, err := s.exportSvc.ExportSelective(r.Context(), req.Domains)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="export.csv"`)
+		w.WriteHeader(http.StatusOK)
+		export.WriteCSV(w)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="export.json"`)
+	respondJSON(w, http.StatusOK, export)
+}
+
 // Scheduler endpoints
 
 // handleSchedulerJobs handles GET /api/v1/scheduler/jobs
@@ -203,10 +839,37 @@ func (s *Server) handleSchedulerJobs(w http.ResponseWriter, r *http.Request) {
 	jobs := s.scheduler.GetScheduledJobs()
 
 	if jobs == nil {
-		jobs = []interface{}{}
+		jobs = []scheduler.ScheduledJobInfo{}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"paused": s.scheduler.IsPaused(),
+		"jobs":   jobs,
+	})
+}
+
+// handleSchedulerPause handles POST /api/v1/scheduler/pause
+func (s *Server) handleSchedulerPause(w http.ResponseWriter, r *http.Request) {
+	if err := s.scheduler.Pause(r.Context()); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"paused":  true,
+		"message": "Scheduled syncs paused; manual triggers still run",
+	})
+}
+
+// handleSchedulerResume handles POST /api/v1/scheduler/resume
+func (s *Server) handleSchedulerResume(w http.ResponseWriter, r *http.Request) {
+	if err := s.scheduler.Resume(r.Context()); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
 	}
 
 	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"jobs": jobs,
+		"paused":  false,
+		"message": "Scheduled syncs resumed",
 	})
 }
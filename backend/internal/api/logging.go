@@ -0,0 +1,50 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"0xdomainsnapshot/pkg/redact"
+)
+
+// requestLogger logs one line per request with secrets redacted
+// Query parameters that look like credentials are replaced. Headers
+// (Authorization, cookies and credential-like names redacted) and the
+// first LogBodyMaxBytes of the request body are only logged when enabled.
+func (s *Server) requestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		var body []byte
+		if s.cfg.LogBodies && r.Body != nil && s.cfg.LogBodyMaxBytes > 0 {
+			body, _ = io.ReadAll(io.LimitReader(r.Body, int64(s.cfg.LogBodyMaxBytes)+1))
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		log.Printf("[HTTP] %s %s from %s - %d %dB in %v (request %s)",
+			r.Method, redact.URL(r.URL.RequestURI()), r.RemoteAddr,
+			ww.Status(), ww.BytesWritten(), time.Since(start), middleware.GetReqID(r.Context()))
+
+		if s.cfg.LogHeaders {
+			log.Printf("[HTTP]   headers: %v", redact.Header(r.Header))
+		}
+		if len(body) > 0 {
+			truncated := ""
+			if len(body) > s.cfg.LogBodyMaxBytes {
+				body, truncated = body[:s.cfg.LogBodyMaxBytes], " (truncated)"
+			}
+			log.Printf("[HTTP]   body%s: %s", truncated, body)
+		}
+	})
+}
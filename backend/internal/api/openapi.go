@@ -0,0 +1,20 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec is the OpenAPI document describing the API
+// It is maintained by hand: update openapi.json when adding or changing
+// an endpoint or a response field.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// handleOpenAPI handles GET /api/v1/openapi.json
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(openAPISpec)
+}
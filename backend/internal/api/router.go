@@ -20,6 +20,7 @@ import (
 type Server struct {
 	router    chi.Router
 	cfg       config.ServerConfig
+	appCfg    *config.Config
 	scheduler *scheduler.Scheduler
 	syncSvc   *service.SyncService
 	exportSvc *service.ExportService
@@ -27,14 +28,15 @@ type Server struct {
 
 // NewServer creates a new API server
 func NewServer(
-	cfg config.ServerConfig,
+	appCfg *config.Config,
 	sched *scheduler.Scheduler,
 	syncSvc *service.SyncService,
 	exportSvc *service.ExportService,
 ) *Server {
 	s := &Server{
 		router:    chi.NewRouter(),
-		cfg:       cfg,
+		cfg:       appCfg.Server,
+		appCfg:    appCfg,
 		scheduler: sched,
 		syncSvc:   syncSvc,
 		exportSvc: exportSvc,
@@ -51,8 +53,8 @@ func (s *Server) setupMiddleware() {
 	// Request ID
 	s.router.Use(middleware.RequestID)
 
-	// Logging
-	s.router.Use(middleware.Logger)
+	// Logging (secrets redacted)
+	s.router.Use(s.requestLogger)
 
 	// Panic recovery
 	s.router.Use(middleware.Recoverer)
@@ -61,12 +63,27 @@ func (s *Server) setupMiddleware() {
 	s.router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-Request-ID"},
-		ExposedHeaders:   []string{"Link", "X-Request-ID"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-None-Match", "X-Request-ID"},
+		ExposedHeaders:   []string{"ETag", "Link", "X-Request-ID"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
 
+	// Request body size limit (after CORS, so its 413 responses carry the
+	// CORS headers)
+	s.router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if s.cfg.MaxBodyBytes > 0 && r.Body != nil {
+				if r.ContentLength > s.cfg.MaxBodyBytes {
+					respondError(w, http.StatusRequestEntityTooLarge, "request body too large")
+					return
+				}
+				r.Body = http.MaxBytesReader(w, r.Body, s.cfg.MaxBodyBytes)
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
 	// Set content type for JSON responses
 	s.router.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -86,23 +103,46 @@ func (s *Server) setupRoutes() {
 		// Health check
 		r.Get("/health", s.handleHealth)
 
+		// API description and effective configuration
+		r.Get("/openapi.json", s.handleOpenAPI)
+		r.Get("/config", s.handleConfig)
+
 		// Sync endpoints
 		r.Route("/sync", func(r chi.Router) {
 			r.Get("/status", s.handleSyncStatus)
 			r.Get("/status/{collector}", s.handleCollectorStatus)
 			r.Post("/trigger/{collector}", s.handleTriggerSync)
 			r.Post("/trigger-all", s.handleTriggerSyncAll)
+			r.Post("/cancel/{collector}", s.handleCancelSync)
+			r.Get("/stats-history", s.handleStatsHistory)
 		})
 
 		// Data endpoints
 		r.Get("/domains", s.handleGetDomains)
+		r.Get("/domains/empty", s.handleGetEmptyDomains)
+		r.Get("/domains/{domain}/records/merged", s.handleGetMergedRecords)
 		r.Get("/dns-records", s.handleGetDNSRecords)
+		r.Get("/stats", s.handleStats)
+		r.Get("/ns-changes", s.handleNSChanges)
+		r.Get("/txt-issues", s.handleTXTIssues)
+		r.Get("/record-issues", s.handleRecordIssues)
+		r.Get("/domain-risks", s.handleDomainRisks)
+		r.Get("/alerts", s.handleAlerts)
+
+		// Security analysis
+		r.Get("/analysis/dangling", s.handleDanglingCNAMEs)
 
-		// Export endpoint
+		// Export endpoints
 		r.Post("/export", s.handleExport)
+		r.Get("/export/sqlite", s.handleExportSQLite)
+		r.Post("/export/selective", s.handleExportSelective)
+		r.Get("/export/snapshots", s.handleExportSnapshots)
+		r.Post("/export/from-snapshot/{id}", s.handleExportFromSnapshot)
 
-		// Scheduler info
+		// Scheduler info and control
 		r.Get("/scheduler/jobs", s.handleSchedulerJobs)
+		r.Post("/scheduler/pause", s.handleSchedulerPause)
+		r.Post("/scheduler/resume", s.handleSchedulerResume)
 	})
 
 	// Serve data/*.json files with JSON content type
@@ -154,6 +194,8 @@ func (s *Server) setupStaticFiles() {
 }
 
 // handleDataFiles serves JSON files from the data directory
+// Files carry an ETag (content hash), so polling clients sending
+// If-None-Match get a 304 instead of the full file when nothing changed.
 func (s *Server) handleDataFiles(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -166,6 +208,14 @@ func (s *Server) handleDataFiles(w http.ResponseWriter, r *http.Request) {
 
 	// Serve from static dir
 	filePath := filepath.Join(s.cfg.StaticDir, "data", path)
+
+	// ServeFile answers If-None-Match with 304 when the ETag matches. It
+	// rejects paths containing "..", so don't hash anything for those.
+	if !strings.Contains(r.URL.Path, "..") {
+		if etag, err := s.exportSvc.ETag(filePath); err == nil && etag != "" {
+			w.Header().Set("ETag", etag)
+		}
+	}
 	http.ServeFile(w, r, filePath)
 }
 
@@ -24,6 +24,7 @@ type Domain struct {
 	DiscoveryDate time.Time              `json:"discovery_date"`
 	LastSeen      time.Time              `json:"last_seen"`
 	RawData       map[string]interface{} `json:"raw_data,omitempty"`
+	Attributes    map[string]string      `json:"attributes,omitempty"` // Provider metadata worth querying (e.g. account, plan)
 }
 
 // DNSRecord represents a DNS record
@@ -34,11 +35,13 @@ type DNSRecord struct {
 	Data          string                 `json:"data"`
 	TTL           int                    `json:"ttl,omitempty"`
 	Priority      int                    `json:"priority,omitempty"`
+	Proxied       bool                   `json:"proxied,omitempty"` // Served through the provider's proxy/CDN (Cloudflare)
 	Source        string                 `json:"source"`
 	Status        string                 `json:"status"`
 	DiscoveryDate time.Time              `json:"discovery_date"`
 	LastSeen      time.Time              `json:"last_seen"`
 	RawData       map[string]interface{} `json:"raw_data,omitempty"`
+	Attributes    map[string]string      `json:"attributes,omitempty"` // Derived tags (e.g. data_valid), stored alongside the record
 }
 
 // CollectorResult holds the results of a collection run
@@ -48,6 +51,12 @@ type CollectorResult struct {
 	StartTime  time.Time
 	EndTime    time.Time
 	Error      error
+	Partial    bool // Collection stopped early (context cancelled), results are incomplete
+
+	// Account is the provider account the results came from, for sources
+	// collected by one collector per account. Only domains of this account
+	// (attribute "account", none for "") are marked removed when missing.
+	Account string
 }
 
 // Stats returns statistics about the collection result
@@ -79,6 +88,16 @@ type Collector interface {
 	Validate() error
 }
 
+// IncrementalCollector is implemented by collectors that can skip data
+// unchanged since a point in time (e.g. Cloudflare's zone modified_on)
+type IncrementalCollector interface {
+	Collector
+
+	// CollectIncremental collects like Collect, but may leave out data the
+	// provider reports as unchanged since the given time
+	CollectIncremental(ctx context.Context, since time.Time) (*CollectorResult, error)
+}
+
 // CollectorStatus represents the current state of a collector
 type CollectorStatus struct {
 	Name       string        `json:"name"`
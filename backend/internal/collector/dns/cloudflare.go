@@ -4,9 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"0xdomainsnapshot/internal/collector"
@@ -16,20 +17,34 @@ import (
 
 // CloudflareCollector collects DNS records from Cloudflare
 type CloudflareCollector struct {
-	cfg    config.CloudflareConfig
-	rate   config.RateLimitConfig
-	client *httpclient.Client
+	cfg        config.CloudflareConfig
+	rate       config.RateLimitConfig
+	client     *httpclient.Client
+	collectPTR bool // Include reverse zones (COLLECT_PTR)
+
+	// Start of the last full run without errors, for FullSyncInterval
+	mu       sync.Mutex
+	lastFull time.Time
 }
 
 // NewCloudflareCollector creates a new Cloudflare collector
-func NewCloudflareCollector(cfg config.CloudflareConfig, rate config.RateLimitConfig) *CloudflareCollector {
+// rate is the global rate limit, used unless cfg.RateLimit overrides it.
+func NewCloudflareCollector(cfg config.CloudflareConfig, rate config.RateLimitConfig, httpCfg config.HTTPConfig) *CloudflareCollector {
+	rate = cfg.RateLimit.Or(rate)
+
 	return &CloudflareCollector{
 		cfg:    cfg,
 		rate:   rate,
-		client: httpclient.New(rate),
+		client: httpclient.New(rate, httpCfg).WithHeaders(cfg.ExtraHeaders),
 	}
 }
 
+// WithPTR sets whether reverse zones and their PTR records are collected
+func (c *CloudflareCollector) WithPTR(enabled bool) *CloudflareCollector {
+	c.collectPTR = enabled
+	return c
+}
+
 // Name returns the collector name
 func (c *CloudflareCollector) Name() string {
 	return "cloudflare_dns"
@@ -55,6 +70,19 @@ func (c *CloudflareCollector) Validate() error {
 
 // Collect performs the DNS record collection
 func (c *CloudflareCollector) Collect(ctx context.Context) (*collector.CollectorResult, error) {
+	return c.collect(ctx, time.Time{})
+}
+
+// CollectIncremental skips fetching records of zones whose modified_on is
+// before since. Falls back to a full run when CLOUDFLARE_INCREMENTAL is off
+// or the last full run is older than CLOUDFLARE_FULL_SYNC_INTERVAL.
+func (c *CloudflareCollector) CollectIncremental(ctx context.Context, since time.Time) (*collector.CollectorResult, error) {
+	return c.collect(ctx, c.modifiedSince(since, time.Now()))
+}
+
+// collect fetches all zones and the records of zones changed since the
+// cutoff (all zones when since is zero)
+func (c *CloudflareCollector) collect(ctx context.Context, since time.Time) (*collector.CollectorResult, error) {
 	result := &collector.CollectorResult{
 		StartTime: time.Now(),
 	}
@@ -67,14 +95,14 @@ func (c *CloudflareCollector) Collect(ctx context.Context) (*collector.Collector
 	}
 
 	// Step 1: Fetch all zones using page-based pagination
-	log.Printf("[Cloudflare] Fetching zones...")
+	slog.Info("Fetching zones", "collector", c.Name())
 	zones, err := c.fetchAllZones(ctx)
 	if err != nil {
 		result.Error = err
 		result.EndTime = time.Now()
 		return result, err
 	}
-	log.Printf("[Cloudflare] Found %d zones", len(zones))
+	slog.Info("Found zones", "collector", c.Name(), "count", len(zones))
 
 	// Convert zones to collector.Domain
 	now := time.Now()
@@ -82,43 +110,91 @@ func (c *CloudflareCollector) Collect(ctx context.Context) (*collector.Collector
 		result.Domains = append(result.Domains, collector.Domain{
 			Domain:        z.name,
 			Registrar:     "Cloudflare",
-			Status:        "active",
+			Status:        z.status,
 			DiscoveryDate: now,
 			LastSeen:      now,
 			RawData:       z.raw,
+			Attributes:    z.attributes,
 		})
 	}
 
+	// Records of unchanged zones are left as they are in the database.
+	// Their domains are not in the merge, so nothing gets marked removed.
+	if !since.IsZero() {
+		slog.Info("Incremental run, skipping unchanged zones", "collector", c.Name(), "since", since.Format(time.RFC3339))
+	}
+
 	// Step 2: Fetch DNS records for each zone
-	log.Printf("[Cloudflare] Fetching DNS records for %d zones...", len(zones))
+	slog.Info("Fetching DNS records", "collector", c.Name(), "zones", len(zones))
+	failed, skipped := 0, 0
 
 	for i, zone := range zones {
 		if ctx.Err() != nil {
 			result.Error = ctx.Err()
+			result.Partial = true
 			break
 		}
 
+		if !since.IsZero() && zone.modifiedOn != nil && zone.modifiedOn.Before(since) {
+			skipped++
+			continue
+		}
+
 		records, err := c.fetchDNSRecords(ctx, zone.id, zone.name)
 		if err != nil {
-			log.Printf("[Cloudflare] Error fetching records for %s: %v", zone.name, err)
+			slog.Error("Fetching records failed", "collector", c.Name(), "domain", zone.name, "error", err)
+			failed++
 			continue
 		}
 
 		result.DNSRecords = append(result.DNSRecords, records...)
+		result.DNSRecords = append(result.DNSRecords, zoneNSRecords(zone, records)...)
 
 		if (i+1)%20 == 0 {
-			log.Printf("[Cloudflare] Processed %d/%d zones, %d records so far",
-				i+1, len(zones), len(result.DNSRecords))
+			slog.Info("Fetching DNS records", "collector", c.Name(),
+				"processed", i+1, "zones", len(zones), "records", len(result.DNSRecords))
 		}
 	}
 
+	// A zone that failed must be fetched again, so force a full run next
+	c.mu.Lock()
+	if failed > 0 {
+		c.lastFull = time.Time{}
+	} else if since.IsZero() && !result.Partial {
+		c.lastFull = result.StartTime
+	}
+	c.mu.Unlock()
+
 	result.EndTime = time.Now()
-	log.Printf("[Cloudflare] Collection complete: %d zones, %d DNS records in %v",
-		len(result.Domains), len(result.DNSRecords), result.Duration())
+	if skipped > 0 {
+		slog.Info("Skipped unchanged zones", "collector", c.Name(), "count", skipped)
+	}
+	slog.Info("Collection complete", "collector", c.Name(), "source", c.Source(),
+		"domains", len(result.Domains), "records", len(result.DNSRecords), "duration", result.Duration())
 
 	return result, nil
 }
 
+// modifiedSince returns the cutoff for skipping unchanged zones
+// Zero means fetch every zone: incremental mode is off, there was no clean
+// full run since startup, or the last one is older than FullSyncInterval.
+func (c *CloudflareCollector) modifiedSince(since, now time.Time) time.Time {
+	if !c.cfg.Incremental || since.IsZero() {
+		return time.Time{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lastFull.IsZero() {
+		return time.Time{}
+	}
+	if c.cfg.FullSyncInterval > 0 && now.Sub(c.lastFull) >= c.cfg.FullSyncInterval {
+		return time.Time{}
+	}
+	return since
+}
+
 // authHeader returns the authorization header for Cloudflare API
 func (c *CloudflareCollector) authHeader() http.Header {
 	return http.Header{
@@ -176,15 +252,72 @@ func (c *CloudflareCollector) verifyToken(ctx context.Context) error {
 
 // cloudflareZone holds zone info from Cloudflare API
 type cloudflareZone struct {
-	id   string
-	name string
-	raw  map[string]interface{}
+	id          string
+	name        string
+	status      string // Zone status: active, pending, initializing, moved, ...
+	nameServers []string
+	modifiedOn  *time.Time
+	attributes  map[string]string
+	raw         map[string]interface{}
+}
+
+// zoneAttributes extracts the owning account and plan from a zone response
+func zoneAttributes(z map[string]interface{}) map[string]string {
+	attrs := make(map[string]string)
+
+	if account, ok := z["account"].(map[string]interface{}); ok {
+		if id, _ := account["id"].(string); id != "" {
+			attrs["account_id"] = id
+		}
+		if name, _ := account["name"].(string); name != "" {
+			attrs["account_name"] = name
+		}
+	}
+	if plan, ok := z["plan"].(map[string]interface{}); ok {
+		if name, _ := plan["name"].(string); name != "" {
+			attrs["plan"] = name
+		}
+	}
+
+	if len(attrs) == 0 {
+		return nil
+	}
+	return attrs
+}
+
+// zoneNSRecords returns apex NS records for the zone's assigned nameservers
+// The dns_records API does not return Cloudflare's own nameservers, so they
+// are taken from the zone's name_servers unless the zone already has apex NS.
+func zoneNSRecords(zone cloudflareZone, records []collector.DNSRecord) []collector.DNSRecord {
+	for _, r := range records {
+		if r.Subdomain == "" && r.RecordType == "NS" {
+			return nil
+		}
+	}
+
+	now := time.Now()
+	var nsRecords []collector.DNSRecord
+	for _, ns := range zone.nameServers {
+		nsRecords = append(nsRecords, collector.DNSRecord{
+			Domain:        zone.name,
+			Subdomain:     "",
+			RecordType:    "NS",
+			Data:          NormalizeRecordData("NS", ns),
+			Source:        "Cloudflare",
+			Status:        "active",
+			DiscoveryDate: now,
+			LastSeen:      now,
+			RawData:       map[string]interface{}{"zone_name_servers": zone.nameServers},
+		})
+	}
+	return nsRecords
 }
 
 // fetchAllZones fetches all zones using page-based pagination
 func (c *CloudflareCollector) fetchAllZones(ctx context.Context) ([]cloudflareZone, error) {
 	var allZones []cloudflareZone
 	page := 1
+	otherAccount, testDomains, reverseZones, inactive := 0, 0, 0, 0
 
 	for {
 		if ctx.Err() != nil {
@@ -196,6 +329,9 @@ func (c *CloudflareCollector) fetchAllZones(ctx context.Context) ([]cloudflareZo
 			"page":     {fmt.Sprintf("%d", page)},
 			"per_page": {fmt.Sprintf("%d", c.cfg.ZonesPerPage)},
 		}
+		if c.cfg.AccountID != "" {
+			params.Set("account.id", c.cfg.AccountID)
+		}
 
 		reqURL := fmt.Sprintf("%s/zones?%s", c.cfg.BaseURL, params.Encode())
 
@@ -224,16 +360,57 @@ func (c *CloudflareCollector) fetchAllZones(ctx context.Context) ([]cloudflareZo
 				continue
 			}
 
+			// The API filters by account already; this catches anything else
+			if c.cfg.AccountID != "" && zoneAttributes(z)["account_id"] != c.cfg.AccountID {
+				otherAccount++
+				continue
+			}
+
 			// Skip test domains
 			if IsTestDomain(name) {
+				testDomains++
 				continue
 			}
 
-			allZones = append(allZones, cloudflareZone{
-				id:   id,
-				name: name,
-				raw:  z,
-			})
+			// Reverse zones only with COLLECT_PTR
+			if IsReverseZone(name) && !c.collectPTR {
+				reverseZones++
+				continue
+			}
+
+			// Zones not activated (or no longer active) only when asked for
+			status, _ := z["status"].(string)
+			if status == "" {
+				status = "active"
+			}
+			if status != "active" && !c.cfg.IncludeInactiveZones {
+				inactive++
+				continue
+			}
+
+			zone := cloudflareZone{
+				id:         id,
+				name:       name,
+				status:     status,
+				attributes: zoneAttributes(z),
+				raw:        z,
+			}
+
+			if modified, ok := z["modified_on"].(string); ok && modified != "" {
+				if t, err := time.Parse(time.RFC3339Nano, modified); err == nil {
+					zone.modifiedOn = &t
+				}
+			}
+
+			if nameServers, ok := z["name_servers"].([]interface{}); ok {
+				for _, ns := range nameServers {
+					if nsName, ok := ns.(string); ok && nsName != "" {
+						zone.nameServers = append(zone.nameServers, nsName)
+					}
+				}
+			}
+
+			allZones = append(allZones, zone)
 		}
 
 		// Check if we've reached the last page
@@ -244,6 +421,19 @@ func (c *CloudflareCollector) fetchAllZones(ctx context.Context) ([]cloudflareZo
 		page++
 	}
 
+	if c.cfg.AccountID != "" {
+		slog.Info("Zones filtered", "collector", c.Name(),
+			"other_account", otherAccount, "account", c.cfg.AccountID, "test_domains", testDomains)
+	} else if testDomains > 0 {
+		slog.Info("Zones filtered", "collector", c.Name(), "test_domains", testDomains)
+	}
+	if reverseZones > 0 {
+		slog.Info("Skipped reverse zones, COLLECT_PTR is off", "collector", c.Name(), "count", reverseZones)
+	}
+	if inactive > 0 {
+		slog.Info("Skipped inactive zones, CLOUDFLARE_INCLUDE_INACTIVE_ZONES is off", "collector", c.Name(), "count", inactive)
+	}
+
 	return allZones, nil
 }
 
@@ -290,6 +480,7 @@ func (c *CloudflareCollector) fetchDNSRecords(ctx context.Context, zoneID, zoneN
 			content, _ := r["content"].(string)
 			ttl, _ := r["ttl"].(float64)
 			priority, _ := r["priority"].(float64)
+			proxied, _ := r["proxied"].(bool)
 
 			// Extract subdomain from full hostname
 			subdomain := ExtractSubdomain(name, zoneName)
@@ -298,14 +489,16 @@ func (c *CloudflareCollector) fetchDNSRecords(ctx context.Context, zoneID, zoneN
 				Domain:        zoneName,
 				Subdomain:     subdomain,
 				RecordType:    NormalizeRecordType(recType),
-				Data:          content,
+				Data:          NormalizeRecordData(recType, content),
 				TTL:           int(ttl),
 				Priority:      int(priority),
+				Proxied:       proxied,
 				Source:        "Cloudflare",
 				Status:        "active",
 				DiscoveryDate: now,
 				LastSeen:      now,
 				RawData:       r,
+				Attributes:    RecordAttributes(recType, content),
 			})
 		}
 
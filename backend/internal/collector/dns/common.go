@@ -1,7 +1,12 @@
 package dns
 
 import (
+	"fmt"
+	"net"
+	"strconv"
 	"strings"
+
+	"0xdomainsnapshot/internal/collector"
 )
 
 // testDomains is a list of test/example domains to filter out
@@ -55,6 +60,13 @@ func IsTestDomain(domain string) bool {
 	return false
 }
 
+// IsReverseZone checks if a zone is a reverse DNS zone holding PTR records
+func IsReverseZone(zone string) bool {
+	z := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(zone)), ".")
+	return z == "in-addr.arpa" || z == "ip6.arpa" ||
+		strings.HasSuffix(z, ".in-addr.arpa") || strings.HasSuffix(z, ".ip6.arpa")
+}
+
 // NormalizeSubdomain normalizes a subdomain value
 // - Converts "@" to empty string (root domain)
 // - Trims whitespace
@@ -93,6 +105,17 @@ func ExtractSubdomain(hostname, domain string) string {
 	return hostname
 }
 
+// FQDN joins a subdomain and its parent domain into a hostname
+// The apex ("" or "@") returns the domain itself.
+// Example: FQDN("www", "example.com") returns "www.example.com"
+func FQDN(subdomain, domain string) string {
+	subdomain = NormalizeSubdomain(subdomain)
+	if subdomain == "" {
+		return domain
+	}
+	return subdomain + "." + domain
+}
+
 // ValidRecordTypes is a list of valid DNS record types
 var ValidRecordTypes = map[string]bool{
 	"A":     true,
@@ -122,3 +145,368 @@ func IsValidRecordType(recordType string) bool {
 func NormalizeRecordType(recordType string) string {
 	return strings.ToUpper(strings.TrimSpace(recordType))
 }
+
+// hostnameRecordTypes are record types whose data is a hostname and
+// therefore case-insensitive. TXT and other types keep their case since
+// it can be significant (e.g. DKIM keys).
+var hostnameRecordTypes = map[string]bool{
+	"CNAME": true,
+	"NS":    true,
+	"MX":    true,
+	"PTR":   true,
+	"SRV":   true,
+}
+
+// IsHostnameRecordType checks if a record type holds a case-insensitive hostname
+func IsHostnameRecordType(recordType string) bool {
+	return hostnameRecordTypes[NormalizeRecordType(recordType)]
+}
+
+// priorityRecordTypes are record types where priority distinguishes records
+var priorityRecordTypes = map[string]bool{
+	"MX":  true,
+	"SRV": true,
+}
+
+// HasSignificantPriority checks if priority is part of a record's identity
+// Two MX records with the same host but different preferences are separate
+// records; for other types priority is not meaningful.
+func HasSignificantPriority(recordType string) bool {
+	return priorityRecordTypes[NormalizeRecordType(recordType)]
+}
+
+// NormalizeRecordData normalizes record data for the given type
+// - Trims whitespace
+// - Lowercases data for hostname-valued types (CNAME, NS, MX, PTR, SRV)
+// - Unquotes and joins TXT (and SPF) character-strings, see NormalizeTXTData
+// - Preserves case for all other types
+func NormalizeRecordData(recordType, data string) string {
+	d := strings.TrimSpace(data)
+	if IsHostnameRecordType(recordType) {
+		return strings.ToLower(d)
+	}
+	switch NormalizeRecordType(recordType) {
+	case "TXT", "SPF":
+		return NormalizeTXTData(d)
+	}
+	return d
+}
+
+// NormalizeTXTData returns TXT data in canonical form: the value with its
+// character-strings unquoted and joined
+// Providers differ in how they return long values (SPF, DKIM keys): some
+// as quoted 255-char chunks ("v=DKIM1; p=MIIB..." "...") and some as one
+// unquoted value, so the same record would otherwise differ between
+// sources and syncs. The zone file export splits values into chunks again.
+func NormalizeTXTData(raw string) string {
+	return strings.Join(SplitTXTStrings(raw), "")
+}
+
+// AttrTXTStrings is the record attribute holding the lengths of the TXT
+// character-strings as the provider returned them ("255,137")
+// Stored TXT data is joined, so this is what CheckTXTData checks the
+// string lengths on.
+const AttrTXTStrings = "txt_strings"
+
+// RecordAttributes returns the attributes derived from record data as the
+// provider returned it, before NormalizeRecordData: AttrTXTStrings for TXT
+// and SPF records, nil for other types
+func RecordAttributes(recordType, data string) map[string]string {
+	switch NormalizeRecordType(recordType) {
+	case "TXT", "SPF":
+		return map[string]string{AttrTXTStrings: TXTStringLengths(data)}
+	}
+	return nil
+}
+
+// TXTStringLengths returns the lengths of the character-strings of TXT data
+// as a comma-separated list (unquoted data is a single string)
+func TXTStringLengths(data string) string {
+	parts := SplitTXTStrings(data)
+	lengths := make([]string, len(parts))
+	for i, part := range parts {
+		lengths[i] = strconv.Itoa(len(part))
+	}
+	return strings.Join(lengths, ",")
+}
+
+// MaxTXTStringLength is the maximum length of a single TXT character-string
+const MaxTXTStringLength = 255
+
+// MaxSPFLookups is the maximum number of DNS lookups allowed by RFC 7208
+const MaxSPFLookups = 10
+
+// SplitTXTStrings splits TXT data into its character-strings
+// Quoted data ("part1" "part2") is split on the quotes and its escapes are
+// decoded: \DDD is the byte with decimal value DDD (\059 is ';') and \X is
+// X itself. Unquoted data is returned as a single string.
+func SplitTXTStrings(data string) []string {
+	d := strings.TrimSpace(data)
+	if !strings.HasPrefix(d, `"`) {
+		return []string{d}
+	}
+
+	var parts []string
+	var current strings.Builder
+	inQuotes := false
+
+	for i := 0; i < len(d); i++ {
+		ch := d[i]
+		switch {
+		case ch == '\\' && inQuotes && i+1 < len(d):
+			i++
+			if b, ok := decimalEscape(d[i:]); ok {
+				current.WriteByte(b)
+				i += 2
+			} else {
+				current.WriteByte(d[i])
+			}
+		case ch == '"':
+			if inQuotes {
+				parts = append(parts, current.String())
+				current.Reset()
+			}
+			inQuotes = !inQuotes
+		case inQuotes:
+			current.WriteByte(ch)
+		}
+	}
+
+	// Unterminated quote: keep what we have
+	if inQuotes {
+		parts = append(parts, current.String())
+	}
+
+	return parts
+}
+
+// decimalEscape decodes the DDD of a \DDD escape at the start of s
+func decimalEscape(s string) (byte, bool) {
+	if len(s) < 3 {
+		return 0, false
+	}
+	n := 0
+	for _, c := range []byte(s[:3]) {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+	if n > 255 {
+		return 0, false
+	}
+	return byte(n), true
+}
+
+// CountSPFLookups counts the DNS-querying terms of an SPF record
+// Only the record's own terms are counted (includes are not followed), so
+// the result is a lower bound of the total lookups at evaluation time.
+func CountSPFLookups(spf string) int {
+	count := 0
+	for _, term := range strings.Fields(strings.ToLower(spf)) {
+		term = strings.TrimLeft(term, "+-~?")
+		name, _, _ := strings.Cut(term, ":")
+		name, _, _ = strings.Cut(name, "/")
+
+		switch {
+		case name == "include", name == "a", name == "mx", name == "ptr", name == "exists":
+			count++
+		case strings.HasPrefix(term, "redirect="):
+			count++
+		}
+	}
+	return count
+}
+
+// CheckTXTData returns a list of issues found in TXT record data
+// - Character-strings longer than 255 chars; a single one means the value
+//   appears unsplit
+// - SPF records exceeding the 10 DNS lookup limit
+// Quoted data is checked string by string. Collected data is stored joined
+// (see NormalizeTXTData), so its strings are taken from stringLengths, the
+// record's AttrTXTStrings attribute; without it the length isn't checked.
+func CheckTXTData(data, stringLengths string) []string {
+	var issues []string
+
+	var lengths []int
+	if strings.HasPrefix(strings.TrimSpace(data), `"`) {
+		for _, part := range SplitTXTStrings(data) {
+			lengths = append(lengths, len(part))
+		}
+	} else if stringLengths != "" {
+		for _, field := range strings.Split(stringLengths, ",") {
+			if n, err := strconv.Atoi(field); err == nil {
+				lengths = append(lengths, n)
+			}
+		}
+	}
+
+	for i, n := range lengths {
+		if n <= MaxTXTStringLength {
+			continue
+		}
+		if len(lengths) == 1 {
+			issues = append(issues, fmt.Sprintf("TXT value is %d chars and appears unsplit (max %d per string)",
+				n, MaxTXTStringLength))
+		} else {
+			issues = append(issues, fmt.Sprintf("TXT string %d is %d chars (max %d)",
+				i+1, n, MaxTXTStringLength))
+		}
+	}
+
+	value := NormalizeTXTData(data)
+	if strings.HasPrefix(strings.ToLower(value), "v=spf1") {
+		if lookups := CountSPFLookups(value); lookups > MaxSPFLookups {
+			issues = append(issues, fmt.Sprintf("SPF record has %d DNS lookups (max %d)", lookups, MaxSPFLookups))
+		}
+	}
+
+	return issues
+}
+
+// isHostname checks if s looks like a DNS hostname
+// Allows a trailing dot, "@" (zone apex), a leading "*" label and underscores
+// (used by service labels such as _dmarc). IP addresses are rejected.
+func isHostname(s string) bool {
+	h := strings.TrimSuffix(s, ".")
+	if h == "@" {
+		return true
+	}
+	if h == "" || len(h) > 253 || net.ParseIP(h) != nil {
+		return false
+	}
+
+	for i, label := range strings.Split(h, ".") {
+		if label == "*" && i == 0 {
+			continue
+		}
+		if label == "" || len(label) > 63 {
+			return false
+		}
+		for _, ch := range label {
+			switch {
+			case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9', ch == '-', ch == '_':
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ValidateRecordData checks that data has the shape expected for the record type
+// - A: IPv4 address, AAAA: IPv6 address
+// - CNAME, NS, PTR: hostname
+// - MX: hostname, or "." (null MX)
+// - SRV: target hostname (optionally preceded by "weight port")
+// - Other types: non-empty
+func ValidateRecordData(recordType, data string) error {
+	d := strings.TrimSpace(data)
+	if d == "" {
+		return fmt.Errorf("empty data")
+	}
+
+	switch NormalizeRecordType(recordType) {
+	case "A":
+		if ip := net.ParseIP(d); ip == nil || ip.To4() == nil {
+			return fmt.Errorf("A data %q is not an IPv4 address", d)
+		}
+	case "AAAA":
+		if ip := net.ParseIP(d); ip == nil || ip.To4() != nil {
+			return fmt.Errorf("AAAA data %q is not an IPv6 address", d)
+		}
+	case "CNAME", "NS", "PTR":
+		if !isHostname(d) {
+			return fmt.Errorf("%s data %q is not a hostname", NormalizeRecordType(recordType), d)
+		}
+	case "MX":
+		if d != "." && !isHostname(d) {
+			return fmt.Errorf("MX data %q is not a hostname", d)
+		}
+	case "SRV":
+		fields := strings.Fields(d)
+		if target := fields[len(fields)-1]; target != "." && !isHostname(target) {
+			return fmt.Errorf("SRV target %q is not a hostname", target)
+		}
+	}
+
+	return nil
+}
+
+// ValidateRecords checks the data of each record against its type
+// Invalid records get the attribute data_valid=false and a data_error
+// message. With strict set they are dropped instead. Returns the records
+// to keep and the number of invalid records found.
+func ValidateRecords(records []collector.DNSRecord, strict bool) ([]collector.DNSRecord, int) {
+	kept := records[:0]
+	invalid := 0
+
+	for _, r := range records {
+		err := ValidateRecordData(r.RecordType, r.Data)
+		if err == nil {
+			kept = append(kept, r)
+			continue
+		}
+
+		invalid++
+		if strict {
+			continue
+		}
+
+		if r.Attributes == nil {
+			r.Attributes = make(map[string]string)
+		}
+		r.Attributes["data_valid"] = "false"
+		r.Attributes["data_error"] = err.Error()
+		kept = append(kept, r)
+	}
+
+	return kept, invalid
+}
+
+// TagFlattenedCNAMEs marks CNAME records that standard DNS can't serve as-is
+// - apex_cname=true: a CNAME at the zone apex (conflicts with SOA/NS)
+// - flattened=true, resolves_as=A/AAAA: the provider answers with the target's addresses
+//
+// Cloudflare flattens every apex CNAME, and other CNAMEs when the record's
+// settings.flatten_cname is set. Returns the number of records tagged.
+func TagFlattenedCNAMEs(records []collector.DNSRecord) int {
+	tagged := 0
+
+	for i := range records {
+		r := &records[i]
+		if r.RecordType != "CNAME" {
+			continue
+		}
+
+		apex := r.Subdomain == ""
+		flattened := (apex && r.Source == "Cloudflare") || flattenSetting(r.RawData)
+		if !apex && !flattened {
+			continue
+		}
+
+		if r.Attributes == nil {
+			r.Attributes = make(map[string]string)
+		}
+		if apex {
+			r.Attributes["apex_cname"] = "true"
+		}
+		if flattened {
+			r.Attributes["flattened"] = "true"
+			r.Attributes["resolves_as"] = "A/AAAA"
+		}
+		tagged++
+	}
+
+	return tagged
+}
+
+// flattenSetting reports whether a Cloudflare record has settings.flatten_cname
+func flattenSetting(raw map[string]interface{}) bool {
+	settings, ok := raw["settings"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	flatten, _ := settings["flatten_cname"].(bool)
+	return flatten
+}
@@ -0,0 +1,165 @@
+package dns
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeRecordDataCase(t *testing.T) {
+	tests := []struct {
+		recordType string
+		data       string
+		want       string
+	}{
+		{"CNAME", " WWW.Example.COM ", "www.example.com"},
+		{"cname", "Target.Example.com", "target.example.com"},
+		{"NS", "NS1.Example.NET", "ns1.example.net"},
+		{"MX", "Mail.Example.com", "mail.example.com"},
+		{"SRV", "5 5060 SIP.Example.com", "5 5060 sip.example.com"},
+		{"TXT", "v=DKIM1; p=MIIBIjANBgkq", "v=DKIM1; p=MIIBIjANBgkq"},
+		{"A", "192.0.2.1", "192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeRecordData(tt.recordType, tt.data); got != tt.want {
+			t.Errorf("NormalizeRecordData(%q, %q) = %q, want %q", tt.recordType, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestHasSignificantPriority(t *testing.T) {
+	tests := []struct {
+		recordType string
+		want       bool
+	}{
+		{"MX", true},
+		{"mx", true},
+		{"SRV", true},
+		{"A", false},
+		{"CNAME", false},
+		{"TXT", false},
+	}
+
+	for _, tt := range tests {
+		if got := HasSignificantPriority(tt.recordType); got != tt.want {
+			t.Errorf("HasSignificantPriority(%q) = %v, want %v", tt.recordType, got, tt.want)
+		}
+	}
+}
+
+// dkimKey is a 2048-bit DKIM public key, longer than one TXT string
+const dkimKey = "MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAu5mFjsG3ePxBiIZd0zJmYJ0KkWi8x6yq" +
+	"RVhOmyBS0U3Z3cHrh0ymXKVmGf8ND3xHXeJ8ajEmHNrWI0Xx6rFMEO1MD2qMgcBXvlBd5g5bcs5YgR" +
+	"dH3QnAeQgVjJm8yG5BOFLU4T6F8yQ0ZzV2VNTsAbp7AZZc6ZUJf7aWhwONV0kcNJj1k9VN7PkTwSz3" +
+	"YVkmEanq4bTXvJr0WBMTdYAUdr2H5TWxdUdzN7tF2bqnvZn2zp0AhBx6TqxgfWNBsvRKhA5JD2v+IB" +
+	"Fc6FzGvGJUf1yMOCGb5pZNRc2hC1c5hQS9Vy8y2tq5yD0uCSDkfgYhcK9e1B5HDFq7k4vXzEBWLmJQIDAQAB"
+
+func TestNormalizeTXTData(t *testing.T) {
+	value := "v=DKIM1; k=rsa; p=" + dkimKey
+	chunk1, chunk2 := value[:MaxTXTStringLength], value[MaxTXTStringLength:]
+
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"unquoted", value, value},
+		{"single quoted string", `"v=spf1 -all"`, "v=spf1 -all"},
+		{"dkim in two chunks", `"` + chunk1 + `" "` + chunk2 + `"`, value},
+		{"dkim chunks without space", `"` + chunk1 + `""` + chunk2 + `"`, value},
+		{"dkim split mid-tag", `"v=DKIM1; k=rsa; p=" "` + dkimKey[:100] + `" "` + dkimKey[100:] + `"`, value},
+		{"padded", "  \"v=spf1 -all\"  ", "v=spf1 -all"},
+		{"decimal escape", `"v=DKIM1\059 k=rsa\059 p=` + dkimKey + `"`, value},
+		{"escaped quote and backslash", `"say \"hi\" \\ bye"`, `say "hi" \ bye`},
+		{"escaped letter", `"\a\b"`, "ab"},
+		{"short digit escape", `"a\05"`, "a05"},
+		{"out of range decimal escape", `"a\300"`, "a300"},
+		{"unterminated", `"v=spf1 -all`, "v=spf1 -all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeTXTData(tt.raw); got != tt.want {
+				t.Errorf("NormalizeTXTData(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeRecordDataJoinsTXTChunks(t *testing.T) {
+	value := "v=DKIM1; p=" + dkimKey
+	quoted := `"` + value[:MaxTXTStringLength] + `" "` + value[MaxTXTStringLength:] + `"`
+
+	for _, recordType := range []string{"TXT", "SPF"} {
+		if got := NormalizeRecordData(recordType, quoted); got != value {
+			t.Errorf("NormalizeRecordData(%q, quoted) = %q, want %q", recordType, got, value)
+		}
+	}
+}
+
+func TestCheckTXTDataLength(t *testing.T) {
+	long := strings.Repeat("a", 300)
+
+	tests := []struct {
+		name          string
+		data          string
+		stringLengths string
+		want          []string
+	}{
+		{"stored unsplit value", long, "300", []string{"TXT value is 300 chars and appears unsplit (max 255 per string)"}},
+		{"stored split value", long, "255,45", nil},
+		{"stored value with a long string", long, "10,290", []string{"TXT string 2 is 290 chars (max 255)"}},
+		{"stored value, lengths unknown", long, "", nil},
+		{"quoted unsplit value", `"` + long + `"`, "", []string{"TXT value is 300 chars and appears unsplit (max 255 per string)"}},
+		{"quoted split value", `"` + long[:255] + `" "` + long[255:] + `"`, "", nil},
+		{"short value", "v=DMARC1; p=reject", "18", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckTXTData(tt.data, tt.stringLengths)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("CheckTXTData = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTXTDataOfCollectedRecord(t *testing.T) {
+	// A provider returning a 300-char DKIM value as one string
+	raw := "v=DKIM1; k=rsa; p=" + strings.Repeat("A", 282)
+
+	data := NormalizeRecordData("TXT", raw)
+	attrs := RecordAttributes("TXT", raw)
+	if issues := CheckTXTData(data, attrs[AttrTXTStrings]); len(issues) != 1 {
+		t.Errorf("unsplit value: issues = %q, want one", issues)
+	}
+
+	// The same value in 255-char strings
+	quoted := `"` + raw[:255] + `" "` + raw[255:] + `"`
+	data = NormalizeRecordData("TXT", quoted)
+	attrs = RecordAttributes("TXT", quoted)
+	if issues := CheckTXTData(data, attrs[AttrTXTStrings]); len(issues) != 0 {
+		t.Errorf("split value: issues = %q, want none", issues)
+	}
+}
+
+func TestRecordAttributes(t *testing.T) {
+	tests := []struct {
+		recordType string
+		data       string
+		want       map[string]string
+	}{
+		{"TXT", `"abc" "de"`, map[string]string{AttrTXTStrings: "3,2"}},
+		{"txt", "hello", map[string]string{AttrTXTStrings: "5"}},
+		{"SPF", `"v=spf1 \059"`, map[string]string{AttrTXTStrings: "8"}},
+		{"A", "192.0.2.1", nil},
+	}
+
+	for _, tt := range tests {
+		if got := RecordAttributes(tt.recordType, tt.data); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("RecordAttributes(%q, %q) = %v, want %v", tt.recordType, tt.data, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	miekgdns "github.com/miekg/dns"
+
+	"0xdomainsnapshot/internal/collector"
+	"0xdomainsnapshot/internal/config"
+	"0xdomainsnapshot/pkg/redact"
+)
+
+// GitZonesCollector collects DNS records from BIND zone files kept in a Git repository
+// This puts the declared (IaC-managed) DNS into the inventory next to what the
+// providers actually serve, so drift between the two can be spotted.
+type GitZonesCollector struct {
+	cfg        config.GitZonesConfig
+	collectPTR bool // Include reverse zones (COLLECT_PTR)
+}
+
+// NewGitZonesCollector creates a new Git zone file collector
+func NewGitZonesCollector(cfg config.GitZonesConfig) *GitZonesCollector {
+	return &GitZonesCollector{cfg: cfg}
+}
+
+// WithPTR sets whether reverse zone files and their PTR records are collected
+func (g *GitZonesCollector) WithPTR(enabled bool) *GitZonesCollector {
+	g.collectPTR = enabled
+	return g
+}
+
+// Name returns the collector name
+func (g *GitZonesCollector) Name() string {
+	return "gitzones_dns"
+}
+
+// Type returns the collector type
+func (g *GitZonesCollector) Type() collector.CollectorType {
+	return collector.CollectorTypeDNSRecords
+}
+
+// Source returns the source name
+func (g *GitZonesCollector) Source() string {
+	return "GitZones"
+}
+
+// Validate checks if the collector is properly configured
+func (g *GitZonesCollector) Validate() error {
+	if g.cfg.RepoURL == "" {
+		return fmt.Errorf("GITZONES_REPO_URL is required")
+	}
+	if g.cfg.CloneDir == "" {
+		return fmt.Errorf("GITZONES_CLONE_DIR is required")
+	}
+	if _, err := filepath.Match(g.cfg.FileGlob, ""); err != nil {
+		return fmt.Errorf("invalid GITZONES_FILE_GLOB: %w", err)
+	}
+	return nil
+}
+
+// Collect clones or pulls the repository and parses all zone files
+// Only DNS records are returned: zone files declare DNS, not registrations.
+func (g *GitZonesCollector) Collect(ctx context.Context) (*collector.CollectorResult, error) {
+	result := &collector.CollectorResult{
+		StartTime: time.Now(),
+	}
+
+	// Step 1: Clone or update the repository
+	slog.Info("Syncing repository", "collector", g.Name(), "repo", redact.URL(g.cfg.RepoURL), "branch", g.cfg.Branch)
+	if err := g.syncRepo(ctx); err != nil {
+		result.Error = fmt.Errorf("sync repository: %w", err)
+		result.EndTime = time.Now()
+		return result, result.Error
+	}
+
+	// Step 2: Find zone files
+	root := filepath.Join(g.cfg.CloneDir, filepath.FromSlash(g.cfg.Subpath))
+	files, err := g.findZoneFiles(root)
+	if err != nil {
+		result.Error = fmt.Errorf("find zone files: %w", err)
+		result.EndTime = time.Now()
+		return result, result.Error
+	}
+	slog.Info("Found zone files", "collector", g.Name(), "count", len(files))
+
+	// Step 3: Parse each zone file
+	for _, path := range files {
+		if ctx.Err() != nil {
+			result.Error = ctx.Err()
+			result.Partial = true
+			break
+		}
+
+		records, err := g.parseZoneFile(path)
+		if err != nil {
+			slog.Error("Parsing zone file failed", "collector", g.Name(), "file", path, "error", err)
+			continue
+		}
+
+		result.DNSRecords = append(result.DNSRecords, records...)
+	}
+
+	result.EndTime = time.Now()
+	slog.Info("Collection complete", "collector", g.Name(), "source", g.Source(),
+		"records", len(result.DNSRecords), "duration", result.Duration())
+
+	return result, nil
+}
+
+// auth returns the HTTP basic auth for the repository, if configured
+func (g *GitZonesCollector) auth() transport.AuthMethod {
+	if g.cfg.Token == "" {
+		return nil
+	}
+	username := g.cfg.Username
+	if username == "" {
+		username = "git" // Most forges accept any username with a token
+	}
+	return &githttp.BasicAuth{Username: username, Password: g.cfg.Token}
+}
+
+// syncRepo clones the repository if needed, otherwise fetches the branch
+// and hard-resets the working copy to it (the clone is never edited locally)
+func (g *GitZonesCollector) syncRepo(ctx context.Context) error {
+	ref := plumbing.NewBranchReferenceName(g.cfg.Branch)
+
+	repo, err := git.PlainOpen(g.cfg.CloneDir)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		_, err = git.PlainCloneContext(ctx, g.cfg.CloneDir, false, &git.CloneOptions{
+			URL:           g.cfg.RepoURL,
+			Auth:          g.auth(),
+			ReferenceName: ref,
+			SingleBranch:  true,
+			Depth:         1,
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	remoteRef := plumbing.NewRemoteReferenceName(git.DefaultRemoteName, g.cfg.Branch)
+	err = repo.FetchContext(ctx, &git.FetchOptions{
+		Auth:     g.auth(),
+		RefSpecs: []gitconfig.RefSpec{gitconfig.RefSpec(fmt.Sprintf("+%s:%s", ref, remoteRef))},
+		Depth:    1,
+		Force:    true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+
+	head, err := repo.Reference(remoteRef, true)
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", remoteRef, err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	return wt.Reset(&git.ResetOptions{Commit: head.Hash(), Mode: git.HardReset})
+}
+
+// findZoneFiles returns all files under root matching the configured glob
+func (g *GitZonesCollector) findZoneFiles(root string) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ok, _ := filepath.Match(g.cfg.FileGlob, d.Name()); ok {
+			files = append(files, path)
+		}
+		return nil
+	})
+
+	return files, err
+}
+
+// zoneOriginFromFilename derives the zone origin from a zone file name
+// Example: "example.com.zone" and "db.example.com" both return "example.com."
+func zoneOriginFromFilename(path string) string {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, "db.") {
+		name = strings.TrimPrefix(name, "db.")
+	} else {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return miekgdns.Fqdn(strings.ToLower(name))
+}
+
+// parseZoneFile parses a BIND zone file into DNS records
+// The zone apex is taken from the SOA owner if present, otherwise from the
+// file name. $INCLUDE is not allowed.
+func (g *GitZonesCollector) parseZoneFile(path string) ([]collector.DNSRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	origin := zoneOriginFromFilename(path)
+	zp := miekgdns.NewZoneParser(f, origin, path)
+
+	var rrs []miekgdns.RR
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		rrs = append(rrs, rr)
+		if soa, isSOA := rr.(*miekgdns.SOA); isSOA {
+			origin = soa.Hdr.Name
+		}
+	}
+	if err := zp.Err(); err != nil {
+		return nil, err
+	}
+
+	zoneName := strings.TrimSuffix(strings.ToLower(origin), ".")
+	if IsTestDomain(zoneName) {
+		return nil, nil
+	}
+	if IsReverseZone(zoneName) && !g.collectPTR {
+		slog.Info("Skipping reverse zone, COLLECT_PTR is off", "collector", g.Name(), "domain", zoneName)
+		return nil, nil
+	}
+
+	now := time.Now()
+	relPath, _ := filepath.Rel(g.cfg.CloneDir, path)
+
+	var records []collector.DNSRecord
+	for _, rr := range rrs {
+		hdr := rr.Header()
+		recType := miekgdns.TypeToString[hdr.Rrtype]
+		data, priority := zoneRecordData(rr)
+
+		records = append(records, collector.DNSRecord{
+			Domain:        zoneName,
+			Subdomain:     ExtractSubdomain(hdr.Name, zoneName),
+			RecordType:    NormalizeRecordType(recType),
+			Data:          NormalizeRecordData(recType, data),
+			TTL:           int(hdr.Ttl),
+			Priority:      priority,
+			Source:        "GitZones",
+			Status:        "active",
+			DiscoveryDate: now,
+			LastSeen:      now,
+			RawData: map[string]interface{}{
+				"file":   filepath.ToSlash(relPath),
+				"branch": g.cfg.Branch,
+				"record": rr.String(),
+			},
+			Attributes: RecordAttributes(recType, data),
+		})
+	}
+
+	return records, nil
+}
+
+// zoneRecordData returns the record data and priority in the same shape
+// the provider collectors use (priority split out for MX/SRV)
+// TXT strings are returned quoted, as in the zone file: miekg/dns keeps
+// their presentation escapes (\; and \059), which NormalizeRecordData
+// decodes while joining the strings.
+func zoneRecordData(rr miekgdns.RR) (string, int) {
+	switch r := rr.(type) {
+	case *miekgdns.A:
+		return r.A.String(), 0
+	case *miekgdns.AAAA:
+		return r.AAAA.String(), 0
+	case *miekgdns.CNAME:
+		return strings.TrimSuffix(r.Target, "."), 0
+	case *miekgdns.NS:
+		return strings.TrimSuffix(r.Ns, "."), 0
+	case *miekgdns.MX:
+		return strings.TrimSuffix(r.Mx, "."), int(r.Preference)
+	case *miekgdns.SRV:
+		return fmt.Sprintf("%d %d %s", r.Weight, r.Port, strings.TrimSuffix(r.Target, ".")), int(r.Priority)
+	case *miekgdns.TXT:
+		return `"` + strings.Join(r.Txt, `" "`) + `"`, 0
+	case *miekgdns.PTR:
+		return strings.TrimSuffix(r.Ptr, "."), 0
+	default:
+		return strings.TrimSpace(strings.TrimPrefix(rr.String(), rr.Header().String())), 0
+	}
+}
@@ -0,0 +1,42 @@
+package dns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"0xdomainsnapshot/internal/config"
+)
+
+func TestParseZoneFileTXTEscapes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "acme-corp.net.zone")
+	zone := `$TTL 300
+sel._domainkey IN TXT "v=DKIM1\; k=rsa\; " "p=MIGfMA0GCSqGSIb3DQEB\059x"
+_spf           IN TXT "v=spf1 include:\"quoted\" \\ -all"
+plain          IN TXT hello
+`
+	if err := os.WriteFile(path, []byte(zone), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGitZonesCollector(config.GitZonesConfig{CloneDir: dir})
+	records, err := g.parseZoneFile(path)
+	if err != nil {
+		t.Fatalf("parseZoneFile: %v", err)
+	}
+
+	want := map[string]string{
+		"sel._domainkey": "v=DKIM1; k=rsa; p=MIGfMA0GCSqGSIb3DQEB;x",
+		"_spf":           `v=spf1 include:"quoted" \ -all`,
+		"plain":          "hello",
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for _, r := range records {
+		if r.Data != want[r.Subdomain] {
+			t.Errorf("%s TXT data = %q, want %q", r.Subdomain, r.Data, want[r.Subdomain])
+		}
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"time"
@@ -16,22 +16,46 @@ import (
 
 // GoDaddyCollector collects DNS records from GoDaddy
 type GoDaddyCollector struct {
-	cfg    config.GoDaddyConfig
-	rate   config.RateLimitConfig
-	client *httpclient.Client
+	cfg        config.GoDaddyConfig
+	rate       config.RateLimitConfig
+	client     *httpclient.Client
+	account    string // Additional customer account, "" for the default one
+	collectPTR bool   // COLLECT_PTR requested (not supported, see Collect)
 }
 
 // NewGoDaddyCollector creates a new GoDaddy collector
-func NewGoDaddyCollector(cfg config.GoDaddyConfig, rate config.RateLimitConfig) *GoDaddyCollector {
+// rate is the global rate limit, used unless cfg.RateLimit overrides it.
+func NewGoDaddyCollector(cfg config.GoDaddyConfig, rate config.RateLimitConfig, httpCfg config.HTTPConfig) *GoDaddyCollector {
+	rate = cfg.RateLimit.Or(rate)
+
 	return &GoDaddyCollector{
 		cfg:    cfg,
 		rate:   rate,
-		client: httpclient.New(rate),
+		client: httpclient.New(rate, httpCfg).WithHeaders(cfg.ExtraHeaders),
 	}
 }
 
+// WithAccount names the customer account the collector's credentials
+// belong to (GODADDY_ACCOUNTS). The collector is then named
+// godaddy_dns_<account>, and its domains record the account in raw_data and
+// attributes so each account's removals stay separate.
+func (g *GoDaddyCollector) WithAccount(account string) *GoDaddyCollector {
+	g.account = account
+	return g
+}
+
+// WithPTR sets whether PTR records are requested; GoDaddy has no reverse
+// zones, so Collect only logs that they are skipped
+func (g *GoDaddyCollector) WithPTR(enabled bool) *GoDaddyCollector {
+	g.collectPTR = enabled
+	return g
+}
+
 // Name returns the collector name
 func (g *GoDaddyCollector) Name() string {
+	if g.account != "" {
+		return "godaddy_dns_" + g.account
+	}
 	return "godaddy_dns"
 }
 
@@ -60,21 +84,34 @@ func (g *GoDaddyCollector) Validate() error {
 func (g *GoDaddyCollector) Collect(ctx context.Context) (*collector.CollectorResult, error) {
 	result := &collector.CollectorResult{
 		StartTime: time.Now(),
+		Account:   g.account,
+	}
+
+	if g.collectPTR {
+		slog.Info("COLLECT_PTR is on, but GoDaddy has no reverse zones", "collector", g.Name())
 	}
 
 	// Step 1: Fetch all domains using marker-based pagination
-	log.Printf("[GoDaddy] Fetching domains...")
+	slog.Info("Fetching domains", "collector", g.Name())
 	domains, err := g.fetchAllDomains(ctx)
 	if err != nil {
 		result.Error = err
 		result.EndTime = time.Now()
 		return result, err
 	}
-	log.Printf("[GoDaddy] Found %d domains", len(domains))
+	slog.Info("Found domains", "collector", g.Name(), "count", len(domains))
 
 	// Convert to collector.Domain
 	now := time.Now()
 	for _, d := range domains {
+		if g.account != "" {
+			d.raw["account"] = g.account
+			if d.attributes == nil {
+				d.attributes = make(map[string]string)
+			}
+			d.attributes["account"] = g.account
+		}
+
 		result.Domains = append(result.Domains, collector.Domain{
 			Domain:        d.domain,
 			Registrar:     "GoDaddy",
@@ -83,16 +120,19 @@ func (g *GoDaddyCollector) Collect(ctx context.Context) (*collector.CollectorRes
 			DiscoveryDate: now,
 			LastSeen:      now,
 			RawData:       d.raw,
+			Attributes:    d.attributes,
 		})
 	}
 
 	// Step 2: Fetch DNS records for each domain
-	log.Printf("[GoDaddy] Fetching DNS records for %d domains...", len(domains))
+	slog.Info("Fetching DNS records", "collector", g.Name(), "domains", len(domains))
 	quotaExceeded := false
+	var accessDenied []string
 
 	for i, domain := range domains {
 		if ctx.Err() != nil {
 			result.Error = ctx.Err()
+			result.Partial = true
 			break
 		}
 
@@ -103,29 +143,38 @@ func (g *GoDaddyCollector) Collect(ctx context.Context) (*collector.CollectorRes
 		records, err := g.fetchDNSRecords(ctx, domain.domain)
 		if err != nil {
 			if httpclient.IsQuotaExceeded(err) {
-				log.Printf("[GoDaddy] Quota exceeded after %d domains", i+1)
+				slog.Warn("Quota exceeded", "collector", g.Name(), "processed", i+1)
 				quotaExceeded = true
 				break
 			}
 			if httpclient.IsNotFound(err) {
-				log.Printf("[GoDaddy] Domain %s not found, skipping", domain.domain)
+				slog.Info("Domain not found, skipping", "collector", g.Name(), "domain", domain.domain)
 				continue
 			}
-			log.Printf("[GoDaddy] Error fetching records for %s: %v", domain.domain, err)
+			if httpclient.IsAccessDenied(err) {
+				accessDenied = append(accessDenied, domain.domain)
+				continue
+			}
+			slog.Error("Fetching records failed", "collector", g.Name(), "domain", domain.domain, "error", err)
 			continue
 		}
 
 		result.DNSRecords = append(result.DNSRecords, records...)
 
 		if (i+1)%50 == 0 {
-			log.Printf("[GoDaddy] Processed %d/%d domains, %d records so far",
-				i+1, len(domains), len(result.DNSRecords))
+			slog.Info("Fetching DNS records", "collector", g.Name(),
+				"processed", i+1, "domains", len(domains), "records", len(result.DNSRecords))
 		}
 	}
 
+	if len(accessDenied) > 0 {
+		slog.Warn("Skipped domains with ACCESS_DENIED", "collector", g.Name(),
+			"count", len(accessDenied), "domains", accessDenied)
+	}
+
 	result.EndTime = time.Now()
-	log.Printf("[GoDaddy] Collection complete: %d domains, %d DNS records in %v",
-		len(result.Domains), len(result.DNSRecords), result.Duration())
+	slog.Info("Collection complete", "collector", g.Name(), "source", g.Source(),
+		"domains", len(result.Domains), "records", len(result.DNSRecords), "duration", result.Duration())
 
 	return result, nil
 }
@@ -140,9 +189,31 @@ func (g *GoDaddyCollector) authHeader() http.Header {
 
 // godaddyDomain holds domain info from GoDaddy API
 type godaddyDomain struct {
-	domain  string
-	expires *time.Time
-	raw     map[string]interface{}
+	domain     string
+	expires    *time.Time
+	attributes map[string]string
+	raw        map[string]interface{}
+}
+
+// domainAttributes extracts the lock, privacy and auto-renew flags from a
+// domain listing entry (stored as "true"/"false"; missing flags are omitted)
+func domainAttributes(d map[string]interface{}) map[string]string {
+	attrs := make(map[string]string)
+
+	for field, attr := range map[string]string{
+		"locked":    "locked",
+		"privacy":   "privacy",
+		"renewAuto": "renew_auto",
+	} {
+		if v, ok := d[field].(bool); ok {
+			attrs[attr] = fmt.Sprintf("%t", v)
+		}
+	}
+
+	if len(attrs) == 0 {
+		return nil
+	}
+	return attrs
 }
 
 // fetchAllDomains fetches all domains using marker-based pagination
@@ -198,8 +269,9 @@ func (g *GoDaddyCollector) fetchAllDomains(ctx context.Context) ([]godaddyDomain
 			}
 
 			gd := godaddyDomain{
-				domain: domainName,
-				raw:    d,
+				domain:     domainName,
+				attributes: domainAttributes(d),
+				raw:        d,
 			}
 
 			// Parse expiry date if present
@@ -272,7 +344,7 @@ func (g *GoDaddyCollector) fetchDNSRecords(ctx context.Context, domain string) (
 				Domain:        domain,
 				Subdomain:     subdomain,
 				RecordType:    NormalizeRecordType(recType),
-				Data:          data,
+				Data:          NormalizeRecordData(recType, data),
 				TTL:           int(ttl),
 				Priority:      int(priority),
 				Source:        "GoDaddy",
@@ -280,6 +352,7 @@ func (g *GoDaddyCollector) fetchDNSRecords(ctx context.Context, domain string) (
 				DiscoveryDate: now,
 				LastSeen:      now,
 				RawData:       r,
+				Attributes:    RecordAttributes(recType, data),
 			})
 		}
 
@@ -0,0 +1,389 @@
+package dns
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"0xdomainsnapshot/internal/collector"
+	"0xdomainsnapshot/internal/config"
+	"0xdomainsnapshot/pkg/httpclient"
+)
+
+// NamecheapCollector collects domains and DNS records from Namecheap
+type NamecheapCollector struct {
+	cfg        config.NamecheapConfig
+	rate       config.RateLimitConfig
+	client     *httpclient.Client
+	collectPTR bool // COLLECT_PTR requested (not supported, see Collect)
+}
+
+// NewNamecheapCollector creates a new Namecheap collector
+// rate is the global rate limit, used unless cfg.RateLimit overrides it.
+func NewNamecheapCollector(cfg config.NamecheapConfig, rate config.RateLimitConfig, httpCfg config.HTTPConfig) *NamecheapCollector {
+	rate = cfg.RateLimit.Or(rate)
+
+	return &NamecheapCollector{
+		cfg:    cfg,
+		rate:   rate,
+		client: httpclient.New(rate, httpCfg).WithHeaders(cfg.ExtraHeaders),
+	}
+}
+
+// WithPTR sets whether PTR records are requested; Namecheap has no reverse
+// zones, so Collect only logs that they are skipped
+func (n *NamecheapCollector) WithPTR(enabled bool) *NamecheapCollector {
+	n.collectPTR = enabled
+	return n
+}
+
+// Name returns the collector name
+func (n *NamecheapCollector) Name() string {
+	return "namecheap_dns"
+}
+
+// Type returns the collector type
+func (n *NamecheapCollector) Type() collector.CollectorType {
+	return collector.CollectorTypeDNSRecords
+}
+
+// Source returns the source name
+func (n *NamecheapCollector) Source() string {
+	return "Namecheap"
+}
+
+// Validate checks if the collector is properly configured
+func (n *NamecheapCollector) Validate() error {
+	if n.cfg.APIUser == "" {
+		return fmt.Errorf("NAMECHEAP_API_USER is required")
+	}
+	if n.cfg.APIKey == "" {
+		return fmt.Errorf("NAMECHEAP_API_KEY is required")
+	}
+	if n.cfg.ClientIP == "" {
+		return fmt.Errorf("NAMECHEAP_CLIENT_IP is required")
+	}
+	return nil
+}
+
+// Collect performs the domain and DNS record collection
+func (n *NamecheapCollector) Collect(ctx context.Context) (*collector.CollectorResult, error) {
+	result := &collector.CollectorResult{
+		StartTime: time.Now(),
+	}
+
+	if n.collectPTR {
+		slog.Info("COLLECT_PTR is on, but Namecheap has no reverse zones", "collector", n.Name())
+	}
+
+	// Step 1: Fetch all domains page by page
+	slog.Info("Fetching domains", "collector", n.Name())
+	domains, err := n.fetchAllDomains(ctx)
+	if err != nil {
+		result.Error = err
+		result.EndTime = time.Now()
+		return result, err
+	}
+	slog.Info("Found domains", "collector", n.Name(), "count", len(domains))
+
+	now := time.Now()
+	for _, d := range domains {
+		result.Domains = append(result.Domains, collector.Domain{
+			Domain:        d.name(),
+			Registrar:     "Namecheap",
+			Status:        "active",
+			ExpiryDate:    d.expires(),
+			DiscoveryDate: now,
+			LastSeen:      now,
+			RawData:       d.Attrs.raw(),
+			Attributes:    d.attributes(),
+		})
+	}
+
+	// Step 2: Fetch host records of domains using Namecheap DNS
+	slog.Info("Fetching DNS records", "collector", n.Name(), "domains", len(domains))
+	var externalDNS []string
+
+	for i, d := range domains {
+		if ctx.Err() != nil {
+			result.Error = ctx.Err()
+			result.Partial = true
+			break
+		}
+
+		// getHosts only works for domains on Namecheap's nameservers
+		if !strings.EqualFold(d.Attrs.get("IsOurDNS"), "true") {
+			externalDNS = append(externalDNS, d.name())
+			continue
+		}
+
+		records, err := n.fetchDNSRecords(ctx, d.name())
+		if err != nil {
+			slog.Error("Fetching records failed", "collector", n.Name(), "domain", d.name(), "error", err)
+			continue
+		}
+		result.DNSRecords = append(result.DNSRecords, records...)
+
+		if (i+1)%50 == 0 {
+			slog.Info("Fetching DNS records", "collector", n.Name(),
+				"processed", i+1, "domains", len(domains), "records", len(result.DNSRecords))
+		}
+	}
+
+	if len(externalDNS) > 0 {
+		slog.Info("Skipped records of domains using external DNS", "collector", n.Name(), "count", len(externalDNS))
+	}
+
+	result.EndTime = time.Now()
+	slog.Info("Collection complete", "collector", n.Name(), "source", n.Source(),
+		"domains", len(result.Domains), "records", len(result.DNSRecords), "duration", result.Duration())
+
+	return result, nil
+}
+
+// namecheapResponse is the envelope of every Namecheap API response
+type namecheapResponse struct {
+	Status string `xml:"Status,attr"`
+	Errors []struct {
+		Number string `xml:"Number,attr"`
+		Text   string `xml:",chardata"`
+	} `xml:"Errors>Error"`
+	CommandResponse struct {
+		Domains []namecheapDomain `xml:"DomainGetListResult>Domain"`
+		Paging  struct {
+			TotalItems  int `xml:"TotalItems"`
+			CurrentPage int `xml:"CurrentPage"`
+			PageSize    int `xml:"PageSize"`
+		} `xml:"Paging"`
+		Hosts struct {
+			Lower []namecheapHost `xml:"host"`
+			Upper []namecheapHost `xml:"Host"`
+		} `xml:"DomainDNSGetHostsResult"`
+	} `xml:"CommandResponse"`
+}
+
+// xmlAttrs are an element's attributes, kept whole as the raw data
+type xmlAttrs []xml.Attr
+
+// get returns an attribute's value, "" if missing
+func (a xmlAttrs) get(name string) string {
+	for _, attr := range a {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// raw returns the attributes as the record's raw data
+func (a xmlAttrs) raw() map[string]interface{} {
+	raw := make(map[string]interface{}, len(a))
+	for _, attr := range a {
+		raw[attr.Name.Local] = attr.Value
+	}
+	return raw
+}
+
+// namecheapDomain is a domain from namecheap.domains.getList
+type namecheapDomain struct {
+	Attrs xmlAttrs `xml:",any,attr"`
+}
+
+// name returns the normalized domain name
+func (d namecheapDomain) name() string {
+	return strings.ToLower(strings.TrimSpace(d.Attrs.get("Name")))
+}
+
+// expires parses the MM/DD/YYYY expiry date, nil if missing or invalid
+func (d namecheapDomain) expires() *time.Time {
+	t, err := time.Parse("01/02/2006", d.Attrs.get("Expires"))
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// attributes returns the lock, privacy and auto-renew flags, named as
+// for GoDaddy domains ("true"/"false"; missing flags are omitted)
+func (d namecheapDomain) attributes() map[string]string {
+	attrs := make(map[string]string)
+
+	if v, err := strconv.ParseBool(d.Attrs.get("IsLocked")); err == nil {
+		attrs["locked"] = strconv.FormatBool(v)
+	}
+	if v, err := strconv.ParseBool(d.Attrs.get("AutoRenew")); err == nil {
+		attrs["renew_auto"] = strconv.FormatBool(v)
+	}
+	if guard := d.Attrs.get("WhoisGuard"); guard != "" {
+		attrs["privacy"] = strconv.FormatBool(strings.EqualFold(guard, "ENABLED"))
+	}
+
+	if len(attrs) == 0 {
+		return nil
+	}
+	return attrs
+}
+
+// namecheapHost is a host record from namecheap.domains.dns.getHosts
+type namecheapHost struct {
+	Attrs xmlAttrs `xml:",any,attr"`
+}
+
+// namecheapRedirectTypes are Namecheap URL redirect hosts, served by their
+// redirect service rather than as DNS records
+var namecheapRedirectTypes = map[string]bool{
+	"URL":    true,
+	"URL301": true,
+	"FRAME":  true,
+}
+
+// call runs an API command and decodes the response
+// Credentials are sent in the POST body so they never appear in URLs
+// (and so in request error messages). Namecheap reports failures with
+// HTTP 200 and Status="ERROR", returned here as a descriptive error.
+func (n *NamecheapCollector) call(ctx context.Context, command string, params url.Values) (*namecheapResponse, error) {
+	username := n.cfg.Username
+	if username == "" {
+		username = n.cfg.APIUser
+	}
+
+	form := url.Values{
+		"ApiUser":  {n.cfg.APIUser},
+		"ApiKey":   {n.cfg.APIKey},
+		"UserName": {username},
+		"ClientIp": {n.cfg.ClientIP},
+		"Command":  {command},
+	}
+	for k, v := range params {
+		form[k] = v
+	}
+
+	headers := http.Header{
+		"Content-Type": []string{"application/x-www-form-urlencoded"},
+		"Accept":       []string{"application/xml"},
+	}
+
+	body, err := n.client.Post(ctx, n.cfg.BaseURL, headers, []byte(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", command, err)
+	}
+
+	var resp namecheapResponse
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("parse %s response: %w", command, err)
+	}
+
+	if !strings.EqualFold(resp.Status, "OK") {
+		var msgs []string
+		for _, e := range resp.Errors {
+			msgs = append(msgs, fmt.Sprintf("%s (error %s)", strings.TrimSpace(e.Text), e.Number))
+		}
+		if len(msgs) == 0 {
+			msgs = append(msgs, "status "+resp.Status)
+		}
+		return nil, fmt.Errorf("%s failed: %s", command, strings.Join(msgs, "; "))
+	}
+
+	return &resp, nil
+}
+
+// fetchAllDomains fetches all domains using page-based pagination
+func (n *NamecheapCollector) fetchAllDomains(ctx context.Context) ([]namecheapDomain, error) {
+	var allDomains []namecheapDomain
+	seen := make(map[string]bool)
+
+	for page := 1; ; page++ {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		resp, err := n.call(ctx, "namecheap.domains.getList", url.Values{
+			"ListType": {"ALL"},
+			"Page":     {strconv.Itoa(page)},
+			"PageSize": {strconv.Itoa(n.cfg.PageSize)},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fetch domains: %w", err)
+		}
+
+		domains := resp.CommandResponse.Domains
+		for _, d := range domains {
+			name := d.name()
+			if name == "" || seen[name] || IsTestDomain(name) {
+				continue
+			}
+			seen[name] = true
+			allDomains = append(allDomains, d)
+		}
+
+		// Last page: short page or all items seen
+		paging := resp.CommandResponse.Paging
+		if len(domains) < n.cfg.PageSize || page*n.cfg.PageSize >= paging.TotalItems {
+			break
+		}
+	}
+
+	return allDomains, nil
+}
+
+// fetchDNSRecords fetches the host records of a domain
+func (n *NamecheapCollector) fetchDNSRecords(ctx context.Context, domain string) ([]collector.DNSRecord, error) {
+	// example.co.uk is SLD "example", TLD "co.uk"
+	sld, tld, ok := strings.Cut(domain, ".")
+	if !ok {
+		return nil, fmt.Errorf("cannot split %q into SLD and TLD", domain)
+	}
+
+	resp, err := n.call(ctx, "namecheap.domains.dns.getHosts", url.Values{
+		"SLD": {sld},
+		"TLD": {tld},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := append(resp.CommandResponse.Hosts.Lower, resp.CommandResponse.Hosts.Upper...)
+	now := time.Now()
+
+	var records []collector.DNSRecord
+	for _, h := range hosts {
+		recType := NormalizeRecordType(h.Attrs.get("Type"))
+		if namecheapRedirectTypes[recType] {
+			continue
+		}
+
+		// Hostname targets come fully qualified ("mail.example.com."),
+		// stored without the trailing dot like the other sources
+		data := h.Attrs.get("Address")
+		if IsHostnameRecordType(recType) {
+			data = strings.TrimSuffix(strings.TrimSpace(data), ".")
+		}
+		ttl, _ := strconv.Atoi(h.Attrs.get("TTL"))
+
+		record := collector.DNSRecord{
+			Domain:        domain,
+			Subdomain:     NormalizeSubdomain(h.Attrs.get("Name")),
+			RecordType:    recType,
+			Data:          NormalizeRecordData(recType, data),
+			TTL:           ttl,
+			Source:        "Namecheap",
+			Status:        "active",
+			DiscoveryDate: now,
+			LastSeen:      now,
+			RawData:       h.Attrs.raw(),
+			Attributes:    RecordAttributes(recType, data),
+		}
+		if recType == "MX" {
+			record.Priority, _ = strconv.Atoi(h.Attrs.get("MXPref"))
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
@@ -8,6 +8,7 @@ import (
 // Registry manages all registered collectors
 type Registry struct {
 	collectors map[string]Collector
+	labels     map[string]map[string]string
 	mu         sync.RWMutex
 }
 
@@ -15,6 +16,7 @@ type Registry struct {
 func NewRegistry() *Registry {
 	return &Registry{
 		collectors: make(map[string]Collector),
+		labels:     make(map[string]map[string]string),
 	}
 }
 
@@ -23,6 +25,12 @@ func NewRegistry() *Registry {
 // - A collector with the same name is already registered
 // - The collector fails validation
 func (r *Registry) Register(c Collector) error {
+	return r.RegisterWithLabels(c, nil)
+}
+
+// RegisterWithLabels adds a collector with optional labels (team, environment, ...)
+// Labels are stored with each sync run and can be used to filter status views.
+func (r *Registry) RegisterWithLabels(c Collector, labels map[string]string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -39,9 +47,20 @@ func (r *Registry) Register(c Collector) error {
 	}
 
 	r.collectors[name] = c
+	if len(labels) > 0 {
+		r.labels[name] = labels
+	}
 	return nil
 }
 
+// Labels returns the labels of a collector (nil if none)
+func (r *Registry) Labels(name string) map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.labels[name]
+}
+
 // Get returns a collector by name
 func (r *Registry) Get(name string) (Collector, bool) {
 	r.mu.RLock()
@@ -118,6 +137,7 @@ func (r *Registry) Unregister(name string) bool {
 
 	if _, exists := r.collectors[name]; exists {
 		delete(r.collectors, name)
+		delete(r.labels, name)
 		return true
 	}
 	return false
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"crypto/tls"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -14,9 +17,14 @@ type Config struct {
 	Database   DatabaseConfig
 	GoDaddy    GoDaddyConfig
 	Cloudflare CloudflareConfig
+	GitZones   GitZonesConfig
+	Namecheap  NamecheapConfig
 	RateLimit  RateLimitConfig
+	HTTP       HTTPConfig
 	Scheduler  SchedulerConfig
+	Sync       SyncConfig
 	Export     ExportConfig
+	Analysis   AnalysisConfig
 }
 
 // ServerConfig holds HTTP server configuration
@@ -24,22 +32,70 @@ type ServerConfig struct {
 	Port      int    `envconfig:"SERVER_PORT" default:"8080"`
 	Host      string `envconfig:"SERVER_HOST" default:"0.0.0.0"`
 	StaticDir string `envconfig:"STATIC_DIR" default:".."`
+
+	// MaxBodyBytes caps request bodies to protect against huge payloads
+	MaxBodyBytes int64 `envconfig:"SERVER_MAX_BODY_BYTES" default:"1048576"`
+
+	// Request logging. Authorization, cookies and credential-like query
+	// parameters and headers are always redacted. Bodies are logged as-is
+	// (up to LogBodyMaxBytes), so only enable them while debugging.
+	LogHeaders      bool `envconfig:"SERVER_LOG_HEADERS" default:"false"`
+	LogBodies       bool `envconfig:"SERVER_LOG_BODIES" default:"false"`
+	LogBodyMaxBytes int  `envconfig:"SERVER_LOG_BODY_MAX_BYTES" default:"2048"`
+
+	// LogFormat is "text" (human-readable) or "json" (one object per line,
+	// for log shippers)
+	LogFormat string `envconfig:"LOG_FORMAT" default:"text"`
 }
 
 // DatabaseConfig holds PostgreSQL configuration
 type DatabaseConfig struct {
-	URL            string `envconfig:"DATABASE_URL" required:"true"`
+	URL            string `envconfig:"DATABASE_URL" required:"true" redact:"url"`
+	ReadURL        string `envconfig:"DATABASE_READ_URL" redact:"url"` // Optional read replica for heavy queries
 	MaxConnections int    `envconfig:"DATABASE_MAX_CONNECTIONS" default:"25"`
 	MaxIdle        int    `envconfig:"DATABASE_MAX_IDLE" default:"5"`
+
+	// Startup retry (for containers where Postgres comes up after us)
+	ConnectAttempts   int           `envconfig:"DATABASE_CONNECT_ATTEMPTS" default:"10"`
+	ConnectRetryDelay time.Duration `envconfig:"DATABASE_CONNECT_RETRY_DELAY" default:"3s"`
 }
 
 // GoDaddyConfig holds GoDaddy API configuration
 type GoDaddyConfig struct {
-	APIKey       string `envconfig:"GODADDY_API_KEY"`
-	APISecret    string `envconfig:"GODADDY_API_SECRET"`
+	APIKey       string `envconfig:"GODADDY_API_KEY" redact:"secret"`
+	APISecret    string `envconfig:"GODADDY_API_SECRET" redact:"secret"`
 	BaseURL      string `envconfig:"GODADDY_BASE_URL" default:"https://api.godaddy.com"`
 	DomainsLimit int    `envconfig:"GODADDY_DOMAINS_LIMIT" default:"1000"`
 	RecordsLimit int    `envconfig:"GODADDY_RECORDS_LIMIT" default:"100"`
+
+	// Labels tag the collector's sync runs, e.g. "team:dns,env:prod"
+	Labels map[string]string `envconfig:"GODADDY_LABELS"`
+
+	// ExtraHeaders are sent with every API request, e.g. "X-Gateway-Token:abc"
+	// (values cannot contain "," or ":")
+	ExtraHeaders map[string]string `envconfig:"GODADDY_EXTRA_HEADERS" redact:"values"`
+
+	// RateLimit overrides the global rate limit for GoDaddy only:
+	// GODADDY_RATE_LIMIT_SLEEP_ON_429, GODADDY_RATE_LIMIT_MAX_RETRIES,
+	// GODADDY_RATE_LIMIT_BACKOFF_FACTOR and GODADDY_RATE_LIMIT_MAX_RETRY_AFTER,
+	// each falling back to RATE_LIMIT_*
+	RateLimit RateLimitConfig `envconfig:"GODADDY"`
+
+	// Accounts names additional GoDaddy customer accounts, e.g.
+	// "acct1,acct2", each with its own GODADDY_<NAME>_API_KEY and
+	// GODADDY_<NAME>_API_SECRET. Every account gets its own collector
+	// (godaddy_dns_<name>); all other settings are shared.
+	Accounts []string `envconfig:"GODADDY_ACCOUNTS"`
+
+	// AccountCredentials holds the credentials read for Accounts
+	AccountCredentials []GoDaddyAccount `ignored:"true"`
+}
+
+// GoDaddyAccount holds the credentials of an additional GoDaddy account
+type GoDaddyAccount struct {
+	Name      string `ignored:"true"`
+	APIKey    string `envconfig:"API_KEY" required:"true"`
+	APISecret string `envconfig:"API_SECRET" required:"true"`
 }
 
 // IsConfigured returns true if GoDaddy credentials are provided
@@ -47,12 +103,48 @@ func (g GoDaddyConfig) IsConfigured() bool {
 	return g.APIKey != "" && g.APISecret != ""
 }
 
+// ForAccount returns the configuration with an additional account's
+// credentials in place of the default ones
+func (g GoDaddyConfig) ForAccount(a GoDaddyAccount) GoDaddyConfig {
+	g.APIKey = a.APIKey
+	g.APISecret = a.APISecret
+	g.Accounts = nil
+	g.AccountCredentials = nil
+	return g
+}
+
 // CloudflareConfig holds Cloudflare API configuration
 type CloudflareConfig struct {
-	APIToken       string `envconfig:"CLOUDFLARE_API_TOKEN"`
+	APIToken       string `envconfig:"CLOUDFLARE_API_TOKEN" redact:"secret"`
 	BaseURL        string `envconfig:"CLOUDFLARE_BASE_URL" default:"https://api.cloudflare.com/client/v4"`
 	ZonesPerPage   int    `envconfig:"CLOUDFLARE_ZONES_PER_PAGE" default:"50"`
 	RecordsPerPage int    `envconfig:"CLOUDFLARE_RECORDS_PER_PAGE" default:"1000"`
+
+	// AccountID limits collection to zones owned by one account (optional)
+	AccountID string `envconfig:"CLOUDFLARE_ACCOUNT_ID"`
+
+	// Labels tag the collector's sync runs, e.g. "team:dns,env:prod"
+	Labels map[string]string `envconfig:"CLOUDFLARE_LABELS"`
+
+	// ExtraHeaders are sent with every API request, e.g. "X-Gateway-Token:abc"
+	// (values cannot contain "," or ":")
+	ExtraHeaders map[string]string `envconfig:"CLOUDFLARE_EXTRA_HEADERS" redact:"values"`
+
+	// RateLimit overrides the global rate limit for Cloudflare only:
+	// CLOUDFLARE_RATE_LIMIT_SLEEP_ON_429, CLOUDFLARE_RATE_LIMIT_MAX_RETRIES,
+	// CLOUDFLARE_RATE_LIMIT_BACKOFF_FACTOR and CLOUDFLARE_RATE_LIMIT_MAX_RETRY_AFTER,
+	// each falling back to RATE_LIMIT_*
+	RateLimit RateLimitConfig `envconfig:"CLOUDFLARE"`
+
+	// Incremental skips fetching records for zones whose modified_on is older
+	// than the last completed sync. A full run still happens on startup, when
+	// a zone has no modified_on, and every FullSyncInterval.
+	Incremental      bool          `envconfig:"CLOUDFLARE_INCREMENTAL" default:"false"`
+	FullSyncInterval time.Duration `envconfig:"CLOUDFLARE_FULL_SYNC_INTERVAL" default:"24h"`
+	// IncludeInactiveZones also collects zones that are not active yet (or
+	// anymore), e.g. pending activation, with their zone status as the
+	// domain status
+	IncludeInactiveZones bool `envconfig:"CLOUDFLARE_INCLUDE_INACTIVE_ZONES" default:"false"`
 }
 
 // IsConfigured returns true if Cloudflare credentials are provided
@@ -60,11 +152,101 @@ func (c CloudflareConfig) IsConfigured() bool {
 	return c.APIToken != ""
 }
 
+// NamecheapConfig holds Namecheap API configuration
+type NamecheapConfig struct {
+	APIUser  string `envconfig:"NAMECHEAP_API_USER"`
+	APIKey   string `envconfig:"NAMECHEAP_API_KEY" redact:"secret"`
+	Username string `envconfig:"NAMECHEAP_USERNAME"`  // Account acted on, defaults to the API user
+	ClientIP string `envconfig:"NAMECHEAP_CLIENT_IP"` // Whitelisted IPv4 address requests come from
+	BaseURL  string `envconfig:"NAMECHEAP_BASE_URL" default:"https://api.namecheap.com/xml.response"`
+	PageSize int    `envconfig:"NAMECHEAP_PAGE_SIZE" default:"100"` // Domains per getList page (max 100)
+
+	// Labels tag the collector's sync runs, e.g. "team:dns,env:prod"
+	Labels map[string]string `envconfig:"NAMECHEAP_LABELS"`
+
+	// ExtraHeaders are sent with every API request, e.g. "X-Gateway-Token:abc"
+	// (values cannot contain "," or ":")
+	ExtraHeaders map[string]string `envconfig:"NAMECHEAP_EXTRA_HEADERS" redact:"values"`
+
+	// RateLimit overrides the global rate limit for Namecheap only:
+	// NAMECHEAP_RATE_LIMIT_SLEEP_ON_429, NAMECHEAP_RATE_LIMIT_MAX_RETRIES,
+	// NAMECHEAP_RATE_LIMIT_BACKOFF_FACTOR and NAMECHEAP_RATE_LIMIT_MAX_RETRY_AFTER,
+	// each falling back to RATE_LIMIT_*
+	RateLimit RateLimitConfig `envconfig:"NAMECHEAP"`
+}
+
+// IsConfigured returns true if Namecheap credentials are provided
+func (n NamecheapConfig) IsConfigured() bool {
+	return n.APIUser != "" && n.APIKey != "" && n.ClientIP != ""
+}
+
+// GitZonesConfig holds configuration for the Git zone file collector
+type GitZonesConfig struct {
+	RepoURL  string `envconfig:"GITZONES_REPO_URL" redact:"url"`
+	Branch   string `envconfig:"GITZONES_BRANCH" default:"main"`
+	Subpath  string `envconfig:"GITZONES_SUBPATH"`                        // Directory within the repo holding zone files
+	FileGlob string `envconfig:"GITZONES_FILE_GLOB" default:"*.zone"`     // Zone file name pattern
+	CloneDir string `envconfig:"GITZONES_CLONE_DIR" default:"./gitzones"` // Local working copy
+	Username string `envconfig:"GITZONES_USERNAME"`
+	Token    string `envconfig:"GITZONES_TOKEN" redact:"secret"` // HTTPS token for private repositories
+
+	// Labels tag the collector's sync runs, e.g. "team:dns,env:prod"
+	Labels map[string]string `envconfig:"GITZONES_LABELS"`
+}
+
+// IsConfigured returns true if a zone repository is configured
+func (g GitZonesConfig) IsConfigured() bool {
+	return g.RepoURL != ""
+}
+
 // RateLimitConfig holds rate limiting configuration
 type RateLimitConfig struct {
 	SleepOn429    time.Duration `envconfig:"RATE_LIMIT_SLEEP_ON_429" default:"30s"`
 	MaxRetries    int           `envconfig:"RATE_LIMIT_MAX_RETRIES" default:"5"`
 	BackoffFactor float64       `envconfig:"RATE_LIMIT_BACKOFF_FACTOR" default:"1.5"`
+
+	// MaxRetryAfter caps the wait a 429 response asks for in its
+	// Retry-After header; SleepOn429 is used when the header is missing
+	MaxRetryAfter time.Duration `envconfig:"RATE_LIMIT_MAX_RETRY_AFTER" default:"5m"`
+}
+
+// Or returns r, or fallback when r is not set
+// Provider overrides loaded by Load are always set, since envconfig falls
+// back to the global RATE_LIMIT_* variables for them.
+func (r RateLimitConfig) Or(fallback RateLimitConfig) RateLimitConfig {
+	if r == (RateLimitConfig{}) {
+		return fallback
+	}
+	return r
+}
+
+// HTTPConfig holds TLS settings for outbound provider API requests
+type HTTPConfig struct {
+	MinTLS string `envconfig:"HTTP_MIN_TLS" default:"1.2"` // 1.0, 1.1, 1.2 or 1.3
+
+	// InsecureSkipVerify disables certificate verification. Only for test
+	// environments talking to stub servers with self-signed certificates.
+	InsecureSkipVerify bool `envconfig:"HTTP_INSECURE_SKIP_VERIFY" default:"false"`
+
+	// Debug logs each outbound request (URL and headers with credentials
+	// redacted) and its response status
+	Debug bool `envconfig:"HTTP_DEBUG" default:"false"`
+}
+
+// TLSMinVersion returns the crypto/tls constant for MinTLS
+func (h HTTPConfig) TLSMinVersion() (uint16, error) {
+	switch h.MinTLS {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2", "":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("HTTP_MIN_TLS must be 1.0, 1.1, 1.2 or 1.3, got %q", h.MinTLS)
+	}
 }
 
 // SchedulerConfig holds scheduler configuration
@@ -72,13 +254,112 @@ type SchedulerConfig struct {
 	Enabled     bool   `envconfig:"SCHEDULER_ENABLED" default:"true"`
 	DNSCron     string `envconfig:"SCHEDULER_DNS_CRON" default:"0 6 * * *"`
 	DomainsCron string `envconfig:"SCHEDULER_DOMAINS_CRON" default:"0 0 * * 0"`
+
+	// Jitter delays each scheduled run by a random duration in [0, Jitter)
+	// so collectors sharing a cron expression don't all fire at once.
+	Jitter time.Duration `envconfig:"SCHEDULER_JITTER" default:"0"`
+
+	// MaxRunDuration cancels a collector run that takes longer; the sync is
+	// then recorded as failed with a timeout error. 0 disables the limit.
+	MaxRunDuration time.Duration `envconfig:"SCHEDULER_MAX_RUN_DURATION" default:"1h"`
+
+	// ExpiryCron runs a check that logs one consolidated warning listing
+	// the active domains expiring within ExpiryWarning. Empty disables it.
+	// ExpiryWarning is also the window for expiry alerts in /api/v1/alerts.
+	ExpiryCron    string        `envconfig:"SCHEDULER_EXPIRY_CRON" default:"0 7 * * *"`
+	ExpiryWarning time.Duration `envconfig:"SCHEDULER_EXPIRY_WARNING" default:"720h"`
+}
+
+// SyncConfig holds sync/merge configuration
+type SyncConfig struct {
+	// PartialMergeGrace is how long a run whose context was cancelled may
+	// still spend merging what it collected. 0 discards partial results.
+	PartialMergeGrace time.Duration `envconfig:"SYNC_PARTIAL_MERGE_GRACE" default:"2m"`
+
+	// RecordValidation checks DNS data against its record type at ingest:
+	// "off", "tag" (mark invalid records with data_valid=false) or "strict"
+	// (drop invalid records).
+	RecordValidation string `envconfig:"SYNC_RECORD_VALIDATION" default:"tag"`
+
+	// ApexCNAME controls handling of apex and flattened CNAME records:
+	// "off" or "tag" (set apex_cname/flattened/resolves_as attributes so
+	// cross-provider checks know the record is served as A/AAAA).
+	ApexCNAME string `envconfig:"SYNC_APEX_CNAME" default:"tag"`
+
+	// SourcePriority orders sources for the merged per-domain view; the first
+	// source that has a record set wins. Unlisted sources rank last.
+	SourcePriority []string `envconfig:"SYNC_SOURCE_PRIORITY" default:"Cloudflare,GoDaddy,Namecheap,GitZones"`
+
+	// SnapshotBeforeMerge copies a source's rows into merge_snapshots before
+	// each merge so a bad run can be rolled back. SnapshotKeep snapshots are
+	// kept per source (0 keeps all).
+	SnapshotBeforeMerge bool `envconfig:"SYNC_SNAPSHOT_BEFORE_MERGE" default:"false"`
+	SnapshotKeep        int  `envconfig:"SYNC_SNAPSHOT_KEEP" default:"3"`
+
+	// IncrementalMaxAge is how recent the last completed sync must be for a
+	// collector supporting it to run incrementally; older (or no) syncs get
+	// a full collection. 0 allows any age.
+	IncrementalMaxAge time.Duration `envconfig:"SYNC_INCREMENTAL_MAX_AGE" default:"48h"`
+
+	// MaxDropPercent guards against mass removals caused by a broken
+	// provider response: when a run finds this much less than the source
+	// has active, nothing is marked removed and the run fails until an
+	// operator confirms it (trigger with confirm_drop=true). 0 disables it.
+	MaxDropPercent int `envconfig:"SYNC_MAX_DROP_PERCENT" default:"50"`
+
+	// CollectPTR includes reverse zones (in-addr.arpa, ip6.arpa) and their
+	// PTR records from providers that have them (Cloudflare, Git zones).
+	// Off by default: reverse zones are skipped.
+	CollectPTR bool `envconfig:"COLLECT_PTR" default:"false"`
 }
 
 // ExportConfig holds JSON export configuration
 type ExportConfig struct {
-	OutputDir string `envconfig:"JSON_OUTPUT_DIR" default:"../data"`
+	OutputDir          string `envconfig:"JSON_OUTPUT_DIR" default:"../data"`
+	IncludeRaw         bool   `envconfig:"EXPORT_INCLUDE_RAW" default:"false"`         // Include provider raw_data (larger files)
+	ConsolidateSources bool   `envconfig:"EXPORT_CONSOLIDATE_SOURCES" default:"false"` // One entry per record seen from several providers
+	ZoneFile           bool   `envconfig:"EXPORT_ZONEFILE" default:"false"`            // Also write zones/<domain>.zone (BIND format)
+
+	// ArchiveDir keeps a copy of every full export so it can be republished
+	// later; empty disables archiving. The newest ArchiveKeep are kept (0 keeps all).
+	ArchiveDir  string `envconfig:"EXPORT_ARCHIVE_DIR"`
+	ArchiveKeep int    `envconfig:"EXPORT_ARCHIVE_KEEP" default:"30"`
+
+	// MaxRecordsPerFile splits domains.json, subdomains.json and removed.json
+	// into <name>.partN.json files of at most this many entries, listed in
+	// <name>.index.json, instead of one file each. 0 writes single files.
+	MaxRecordsPerFile int `envconfig:"EXPORT_MAX_RECORDS_PER_FILE" default:"0"`
+
+	// MaxAge is how old metadata.json's last_updated may be before the
+	// published export counts as stale (checked at startup and reported by
+	// /health?deep=true); 0 disables the check. With SyncOnStaleStart a
+	// stale export at startup triggers an immediate sync of all collectors.
+	MaxAge           time.Duration `envconfig:"EXPORT_MAX_AGE" default:"48h"`
+	SyncOnStaleStart bool          `envconfig:"EXPORT_SYNC_ON_STALE_START" default:"false"`
+}
+
+// AnalysisConfig holds security analysis configuration
+type AnalysisConfig struct {
+	// OwnedSuffixes are CNAME target suffixes we control (e.g. our own CDN
+	// or load balancer zones); records pointing there are never takeover
+	// candidates. Targets inside our own inventory are always excluded.
+	OwnedSuffixes []string `envconfig:"ANALYSIS_OWNED_SUFFIXES"`
+
+	// SkipWildcards leaves wildcard records (*.example.com) out of the
+	// takeover analysis
+	SkipWildcards bool `envconfig:"ANALYSIS_SKIP_WILDCARDS" default:"true"`
+
+	// Resolver is the DNS server (host:port) for dangling CNAME lookups,
+	// empty for the system resolver. ResolverTimeout bounds each lookup and
+	// ResolverConcurrency the lookups in flight.
+	Resolver            string        `envconfig:"ANALYSIS_RESOLVER"`
+	ResolverTimeout     time.Duration `envconfig:"ANALYSIS_RESOLVER_TIMEOUT" default:"3s"`
+	ResolverConcurrency int           `envconfig:"ANALYSIS_RESOLVER_CONCURRENCY" default:"10"`
 }
 
+// accountNamePattern matches names usable in env variable and collector names
+var accountNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
 // Load loads configuration from environment variables and .env file
 func Load() (*Config, error) {
 	// Load .env file if it exists (optional - environment variables take precedence)
@@ -100,27 +381,66 @@ func Load() (*Config, error) {
 	if err := envconfig.Process("", &cfg.GoDaddy); err != nil {
 		return nil, fmt.Errorf("failed to process GoDaddy config: %w", err)
 	}
+	for _, name := range cfg.GoDaddy.Accounts {
+		if !accountNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid GODADDY_ACCOUNTS name %q: use letters, digits and underscores", name)
+		}
+		acct := GoDaddyAccount{Name: strings.ToLower(name)}
+		if err := envconfig.Process("GODADDY_"+strings.ToUpper(name), &acct); err != nil {
+			return nil, fmt.Errorf("failed to process GoDaddy account %s config: %w", name, err)
+		}
+		if acct.APIKey == "" || acct.APISecret == "" {
+			return nil, fmt.Errorf("GoDaddy account %s: GODADDY_%s_API_KEY and GODADDY_%s_API_SECRET are required",
+				name, strings.ToUpper(name), strings.ToUpper(name))
+		}
+		cfg.GoDaddy.AccountCredentials = append(cfg.GoDaddy.AccountCredentials, acct)
+	}
 
 	// Process Cloudflare config (optional)
 	if err := envconfig.Process("", &cfg.Cloudflare); err != nil {
 		return nil, fmt.Errorf("failed to process Cloudflare config: %w", err)
 	}
 
+	// Process Git zones config (optional)
+	if err := envconfig.Process("", &cfg.GitZones); err != nil {
+		return nil, fmt.Errorf("failed to process Git zones config: %w", err)
+	}
+
+	// Process Namecheap config (optional)
+	if err := envconfig.Process("", &cfg.Namecheap); err != nil {
+		return nil, fmt.Errorf("failed to process Namecheap config: %w", err)
+	}
+
 	// Process rate limit config
 	if err := envconfig.Process("", &cfg.RateLimit); err != nil {
 		return nil, fmt.Errorf("failed to process rate limit config: %w", err)
 	}
 
+	// Process HTTP client config
+	if err := envconfig.Process("", &cfg.HTTP); err != nil {
+		return nil, fmt.Errorf("failed to process HTTP config: %w", err)
+	}
+
 	// Process scheduler config
 	if err := envconfig.Process("", &cfg.Scheduler); err != nil {
 		return nil, fmt.Errorf("failed to process scheduler config: %w", err)
 	}
 
+	// Process sync config
+	if err := envconfig.Process("", &cfg.Sync); err != nil {
+		return nil, fmt.Errorf("failed to process sync config: %w", err)
+	}
+
 	// Process export config
 	if err := envconfig.Process("", &cfg.Export); err != nil {
 		return nil, fmt.Errorf("failed to process export config: %w", err)
 	}
 
+	// Process analysis config
+	if err := envconfig.Process("", &cfg.Analysis); err != nil {
+		return nil, fmt.Errorf("failed to process analysis config: %w", err)
+	}
+
 	return &cfg, nil
 }
 
@@ -130,8 +450,38 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("DATABASE_URL is required")
 	}
 
-	if !c.GoDaddy.IsConfigured() && !c.Cloudflare.IsConfigured() {
-		return fmt.Errorf("at least one provider (GoDaddy or Cloudflare) must be configured")
+	if !c.GoDaddy.IsConfigured() && len(c.GoDaddy.AccountCredentials) == 0 && !c.Cloudflare.IsConfigured() && !c.GitZones.IsConfigured() && !c.Namecheap.IsConfigured() {
+		return fmt.Errorf("at least one provider (GoDaddy, Cloudflare, Git zones or Namecheap) must be configured")
+	}
+
+	if _, err := c.HTTP.TLSMinVersion(); err != nil {
+		return err
+	}
+
+	switch c.Server.LogFormat {
+	case "text", "json":
+	default:
+		return fmt.Errorf("LOG_FORMAT must be text or json, got %q", c.Server.LogFormat)
+	}
+
+	switch c.Sync.RecordValidation {
+	case "off", "tag", "strict":
+	default:
+		return fmt.Errorf("SYNC_RECORD_VALIDATION must be off, tag or strict, got %q", c.Sync.RecordValidation)
+	}
+
+	switch c.Sync.ApexCNAME {
+	case "off", "tag":
+	default:
+		return fmt.Errorf("SYNC_APEX_CNAME must be off or tag, got %q", c.Sync.ApexCNAME)
+	}
+
+	if c.Sync.MaxDropPercent < 0 || c.Sync.MaxDropPercent > 100 {
+		return fmt.Errorf("SYNC_MAX_DROP_PERCENT must be between 0 and 100, got %d", c.Sync.MaxDropPercent)
+	}
+
+	if c.Analysis.ResolverConcurrency < 1 {
+		return fmt.Errorf("ANALYSIS_RESOLVER_CONCURRENCY must be at least 1, got %d", c.Analysis.ResolverConcurrency)
 	}
 
 	return nil
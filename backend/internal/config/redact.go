@@ -0,0 +1,83 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"0xdomainsnapshot/pkg/redact"
+)
+
+// redactedValue replaces secret values in the effective configuration
+const redactedValue = redact.Value
+
+// Redacted returns the effective configuration with all secrets removed
+// The result is keyed by section (server, database, ...) and then by
+// environment variable name, so it can be compared directly with a .env file.
+// Fields are redacted according to their `redact` tag:
+// - "secret": the value is replaced (empty values stay empty)
+// - "url": credentials embedded in the URL are replaced
+// - "values": map keys are kept, values are replaced
+func (c *Config) Redacted() map[string]map[string]interface{} {
+	result := make(map[string]map[string]interface{})
+
+	cv := reflect.ValueOf(*c)
+	ct := cv.Type()
+	for i := 0; i < ct.NumField(); i++ {
+		section := make(map[string]interface{})
+
+		redactSection(section, "", cv.Field(i))
+		result[strings.ToLower(ct.Field(i).Name)] = section
+	}
+
+	return result
+}
+
+// redactSection adds a config struct's fields to section
+// Nested structs (per-provider rate limits) are flattened with their tag as
+// prefix, matching how envconfig names their variables.
+func redactSection(section map[string]interface{}, prefix string, sv reflect.Value) {
+	st := sv.Type()
+	for j := 0; j < st.NumField(); j++ {
+		field := st.Field(j)
+		name := field.Tag.Get("envconfig")
+		if name == "" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		if sv.Field(j).Kind() == reflect.Struct {
+			redactSection(section, name, sv.Field(j))
+			continue
+		}
+		section[name] = redactField(field.Tag.Get("redact"), sv.Field(j))
+	}
+}
+
+// redactField returns a field's value with the given redaction applied
+func redactField(mode string, v reflect.Value) interface{} {
+	switch mode {
+	case "secret":
+		if v.IsZero() {
+			return ""
+		}
+		return redactedValue
+	case "url":
+		return redact.URL(v.String())
+	case "values":
+		if v.Len() == 0 {
+			return nil
+		}
+		keys := make(map[string]string, v.Len())
+		for _, k := range v.MapKeys() {
+			keys[k.String()] = redactedValue
+		}
+		return keys
+	default:
+		if d, ok := v.Interface().(interface{ String() string }); ok {
+			return d.String() // Durations as "30s" rather than nanoseconds
+		}
+		return v.Interface()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -12,13 +13,38 @@ import (
 )
 
 // DB wraps the SQL database connection
+// Writes always go to the primary pool; read-heavy queries can use Reader()
+// which returns the read replica pool when one is configured.
 type DB struct {
 	*sql.DB
+	read *sql.DB
 }
 
 // New creates a new database connection
 func New(cfg config.DatabaseConfig) (*DB, error) {
-	db, err := sql.Open("postgres", cfg.URL)
+	db, err := open(cfg, cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DB{DB: db}
+
+	// Optional read replica
+	if cfg.ReadURL != "" {
+		read, err := open(cfg, cfg.ReadURL)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("read replica: %w", err)
+		}
+		result.read = read
+	}
+
+	return result, nil
+}
+
+// open opens and verifies a connection pool for the given URL
+func open(cfg config.DatabaseConfig, url string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -28,19 +54,56 @@ func New(cfg config.DatabaseConfig) (*DB, error) {
 	db.SetMaxIdleConns(cfg.MaxIdle)
 	db.SetConnMaxLifetime(time.Hour)
 
-	// Verify connection
+	// Verify connection, retrying while the database starts up
+	attempts := cfg.ConnectAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if lastErr = ping(db); lastErr == nil {
+			return db, nil
+		}
+
+		log.Printf("[Database] Connection attempt %d/%d failed: %v", attempt, attempts, lastErr)
+
+		if attempt < attempts {
+			time.Sleep(cfg.ConnectRetryDelay)
+		}
+	}
+
+	db.Close()
+	return nil, fmt.Errorf("failed to ping database after %d attempts: %w", attempts, lastErr)
+}
+
+// ping verifies the connection with a timeout
+func ping(db *sql.DB) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := db.PingContext(ctx); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+	return db.PingContext(ctx)
+}
+
+// Reader returns the pool to use for read-only queries
+// Falls back to the primary pool when no read replica is configured.
+func (db *DB) Reader() *sql.DB {
+	if db.read != nil {
+		return db.read
 	}
+	return db.DB
+}
 
-	return &DB{db}, nil
+// HasReadReplica returns true if a separate read pool is configured
+func (db *DB) HasReadReplica() bool {
+	return db.read != nil
 }
 
-// Close closes the database connection
+// Close closes the database connections
 func (db *DB) Close() error {
+	if db.read != nil {
+		db.read.Close()
+	}
 	return db.DB.Close()
 }
 
@@ -59,19 +122,144 @@ func (db *DB) RunMigrations(ctx context.Context) error {
 		return fmt.Errorf("failed to check if tables exist: %w", err)
 	}
 
-	if exists {
-		return nil // Tables already exist
+	if !exists {
+		// Run initial migration
+		if _, err := db.ExecContext(ctx, migrationSQL); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
 	}
 
-	// Run migration
-	_, err = db.ExecContext(ctx, migrationSQL)
-	if err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+	// Apply schema upgrades (idempotent, safe to run on every startup)
+	for _, u := range upgrades {
+		if _, err := db.ExecContext(ctx, u.sql); err != nil {
+			return fmt.Errorf("failed to apply migration %s: %w", u.name, err)
+		}
 	}
 
 	return nil
 }
 
+// upgrades contains schema changes applied on top of the initial schema
+// Each statement must be idempotent since upgrades run on every startup.
+// Keep in sync with the numbered files in migrations/.
+var upgrades = []struct {
+	name string
+	sql  string
+}{
+	{"002_ns_tracking", `
+-- Current nameserver set per domain and source
+CREATE TABLE IF NOT EXISTS ns_sets (
+    domain          VARCHAR(255) NOT NULL,
+    source          VARCHAR(50) NOT NULL,
+    nameservers     TEXT NOT NULL,
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    PRIMARY KEY (domain, source)
+);
+
+-- Detected nameserver (delegation) changes
+CREATE TABLE IF NOT EXISTS ns_changes (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    domain          VARCHAR(255) NOT NULL,
+    source          VARCHAR(50) NOT NULL,
+    previous_ns     TEXT NOT NULL,
+    current_ns      TEXT NOT NULL,
+    detected_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ns_changes_detected ON ns_changes(detected_at DESC);
+`},
+	{"003_sync_labels", `
+ALTER TABLE sync_status ADD COLUMN IF NOT EXISTS labels JSONB;
+CREATE INDEX IF NOT EXISTS idx_sync_status_labels ON sync_status USING GIN (labels);
+`},
+	{"004_dns_proxied", `
+ALTER TABLE dns_records ADD COLUMN IF NOT EXISTS proxied BOOLEAN NOT NULL DEFAULT false;
+`},
+	{"005_scheduler_state", `
+CREATE TABLE IF NOT EXISTS scheduler_state (
+    key VARCHAR(100) PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+`},
+	{"006_dns_attributes", `
+ALTER TABLE dns_records ADD COLUMN IF NOT EXISTS attributes JSONB;
+CREATE INDEX IF NOT EXISTS idx_dns_records_attributes ON dns_records USING GIN (attributes);
+`},
+	{"007_domain_attributes", `
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS attributes JSONB;
+CREATE INDEX IF NOT EXISTS idx_domains_attributes ON domains USING GIN (attributes);
+`},
+	{"008_last_present_at", `
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS last_present_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE dns_records ADD COLUMN IF NOT EXISTS last_present_at TIMESTAMP WITH TIME ZONE;
+UPDATE domains SET last_present_at = last_seen WHERE last_present_at IS NULL;
+UPDATE dns_records SET last_present_at = last_seen WHERE last_present_at IS NULL;
+`},
+	{"009_merge_snapshots", `
+-- Point-in-time copies of a source's rows, taken before a merge
+CREATE TABLE IF NOT EXISTS merge_snapshots (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source          VARCHAR(50) NOT NULL,
+    domains         INTEGER NOT NULL DEFAULT 0,
+    dns_records     INTEGER NOT NULL DEFAULT 0,
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Rows are stored as JSONB so snapshots survive later column changes
+CREATE TABLE IF NOT EXISTS merge_snapshot_rows (
+    snapshot_id     UUID NOT NULL REFERENCES merge_snapshots(id) ON DELETE CASCADE,
+    table_name      VARCHAR(50) NOT NULL,
+    row_data        JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_merge_snapshots_source ON merge_snapshots(source, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_merge_snapshot_rows_snapshot ON merge_snapshot_rows(snapshot_id);
+`},
+	{"010_priority_signature", `
+-- MX/SRV records with the same data but a different priority are distinct
+ALTER TABLE dns_records DROP CONSTRAINT IF EXISTS dns_records_domain_subdomain_record_type_data_source_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dns_records_signature ON dns_records (
+    domain, subdomain, record_type, data, source,
+    (CASE WHEN record_type IN ('MX', 'SRV') THEN COALESCE(priority, 0) ELSE 0 END)
+);
+`},
+	{"011_case_insensitive_signature", `
+-- Store existing hostname data lowercased, as the merger does, keeping one
+-- row per case-insensitive signature (active first, then most recently seen)
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'idx_dns_records_signature_ci') THEN
+        DELETE FROM dns_records WHERE id IN (
+            SELECT id FROM (
+                SELECT id, ROW_NUMBER() OVER (
+                    PARTITION BY domain, subdomain, record_type, lower(data), source,
+                        (CASE WHEN record_type IN ('MX', 'SRV') THEN COALESCE(priority, 0) ELSE 0 END)
+                    ORDER BY (status = 'active') DESC, last_seen DESC, updated_at DESC NULLS LAST
+                ) AS n
+                FROM dns_records
+                WHERE record_type IN ('CNAME', 'NS', 'MX', 'PTR', 'SRV')
+            ) ranked
+            WHERE n > 1
+        );
+
+        UPDATE dns_records SET data = lower(data)
+        WHERE record_type IN ('CNAME', 'NS', 'MX', 'PTR', 'SRV') AND data <> lower(data);
+    END IF;
+END $$;
+
+-- Rows differing only in the case of hostname data share a signature
+-- (idx_dns_records_signature is kept: 010 re-creates it on every startup)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dns_records_signature_ci ON dns_records (
+    domain, subdomain, record_type,
+    (CASE WHEN record_type IN ('CNAME', 'NS', 'MX', 'PTR', 'SRV') THEN lower(data) ELSE data END),
+    source,
+    (CASE WHEN record_type IN ('MX', 'SRV') THEN COALESCE(priority, 0) ELSE 0 END)
+);
+`},
+}
+
 // migrationSQL contains the initial database schema
 const migrationSQL = `
 -- Enable UUID extension
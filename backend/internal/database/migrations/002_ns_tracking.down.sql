@@ -0,0 +1,7 @@
+-- 002_ns_tracking.down.sql
+-- Rollback nameserver set tracking
+
+DROP INDEX IF EXISTS idx_ns_changes_detected;
+
+DROP TABLE IF EXISTS ns_changes;
+DROP TABLE IF EXISTS ns_sets;
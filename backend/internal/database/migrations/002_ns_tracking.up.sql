@@ -0,0 +1,24 @@
+-- 002_ns_tracking.up.sql
+-- Nameserver set tracking for delegation change detection
+
+-- Current nameserver set per domain and source
+CREATE TABLE IF NOT EXISTS ns_sets (
+    domain          VARCHAR(255) NOT NULL,
+    source          VARCHAR(50) NOT NULL,  -- 'GoDaddy', 'Cloudflare'
+    nameservers     TEXT NOT NULL,  -- Sorted, comma-separated
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    PRIMARY KEY (domain, source)
+);
+
+-- Detected nameserver (delegation) changes
+CREATE TABLE IF NOT EXISTS ns_changes (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    domain          VARCHAR(255) NOT NULL,
+    source          VARCHAR(50) NOT NULL,
+    previous_ns     TEXT NOT NULL,
+    current_ns      TEXT NOT NULL,
+    detected_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ns_changes_detected ON ns_changes(detected_at DESC);
@@ -0,0 +1,6 @@
+-- 003_sync_labels.down.sql
+-- Rollback collector labels
+
+DROP INDEX IF EXISTS idx_sync_status_labels;
+
+ALTER TABLE sync_status DROP COLUMN IF EXISTS labels;
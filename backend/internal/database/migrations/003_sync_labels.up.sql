@@ -0,0 +1,6 @@
+-- 003_sync_labels.up.sql
+-- Collector labels (team, environment, ...) stored with each sync run
+
+ALTER TABLE sync_status ADD COLUMN IF NOT EXISTS labels JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_sync_status_labels ON sync_status USING GIN (labels);
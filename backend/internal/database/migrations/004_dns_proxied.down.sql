@@ -0,0 +1,4 @@
+-- 004_dns_proxied.down.sql
+-- Rollback Cloudflare proxied flag
+
+ALTER TABLE dns_records DROP COLUMN IF EXISTS proxied;
@@ -0,0 +1,4 @@
+-- 004_dns_proxied.up.sql
+-- Cloudflare proxied flag (record resolves to edge IPs, not its data)
+
+ALTER TABLE dns_records ADD COLUMN IF NOT EXISTS proxied BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,4 @@
+-- 005_scheduler_state.down.sql
+-- Rollback persisted scheduler state
+
+DROP TABLE IF EXISTS scheduler_state;
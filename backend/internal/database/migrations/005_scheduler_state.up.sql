@@ -0,0 +1,8 @@
+-- 005_scheduler_state.up.sql
+-- Persisted scheduler state (global pause)
+
+CREATE TABLE IF NOT EXISTS scheduler_state (
+    key VARCHAR(100) PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+-- 006_dns_attributes.down.sql
+-- Rollback derived per-record attributes
+
+DROP INDEX IF EXISTS idx_dns_records_attributes;
+ALTER TABLE dns_records DROP COLUMN IF EXISTS attributes;
@@ -0,0 +1,5 @@
+-- 006_dns_attributes.up.sql
+-- Derived per-record attributes (e.g. data_valid)
+
+ALTER TABLE dns_records ADD COLUMN IF NOT EXISTS attributes JSONB;
+CREATE INDEX IF NOT EXISTS idx_dns_records_attributes ON dns_records USING GIN (attributes);
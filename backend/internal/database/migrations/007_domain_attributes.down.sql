@@ -0,0 +1,5 @@
+-- 007_domain_attributes.down.sql
+-- Rollback provider metadata per domain
+
+DROP INDEX IF EXISTS idx_domains_attributes;
+ALTER TABLE domains DROP COLUMN IF EXISTS attributes;
@@ -0,0 +1,5 @@
+-- 007_domain_attributes.up.sql
+-- Provider metadata per domain (e.g. Cloudflare account and plan)
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS attributes JSONB;
+CREATE INDEX IF NOT EXISTS idx_domains_attributes ON domains USING GIN (attributes);
@@ -0,0 +1,5 @@
+-- 008_last_present_at.down.sql
+-- Rollback last_present_at tracking
+
+ALTER TABLE dns_records DROP COLUMN IF EXISTS last_present_at;
+ALTER TABLE domains DROP COLUMN IF EXISTS last_present_at;
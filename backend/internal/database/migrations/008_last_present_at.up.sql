@@ -0,0 +1,7 @@
+-- 008_last_present_at.up.sql
+-- When a domain/record was last actually returned by its provider
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS last_present_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE dns_records ADD COLUMN IF NOT EXISTS last_present_at TIMESTAMP WITH TIME ZONE;
+UPDATE domains SET last_present_at = last_seen WHERE last_present_at IS NULL;
+UPDATE dns_records SET last_present_at = last_seen WHERE last_present_at IS NULL;
@@ -0,0 +1,5 @@
+-- 009_merge_snapshots.down.sql
+-- Rollback merge snapshots
+
+DROP TABLE IF EXISTS merge_snapshot_rows;
+DROP TABLE IF EXISTS merge_snapshots;
@@ -0,0 +1,21 @@
+-- 009_merge_snapshots.up.sql
+-- Snapshots of affected rows taken before a merge (for rollback)
+
+-- Point-in-time copies of a source's rows, taken before a merge
+CREATE TABLE IF NOT EXISTS merge_snapshots (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source          VARCHAR(50) NOT NULL,
+    domains         INTEGER NOT NULL DEFAULT 0,
+    dns_records     INTEGER NOT NULL DEFAULT 0,
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Rows are stored as JSONB so snapshots survive later column changes
+CREATE TABLE IF NOT EXISTS merge_snapshot_rows (
+    snapshot_id     UUID NOT NULL REFERENCES merge_snapshots(id) ON DELETE CASCADE,
+    table_name      VARCHAR(50) NOT NULL,
+    row_data        JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_merge_snapshots_source ON merge_snapshots(source, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_merge_snapshot_rows_snapshot ON merge_snapshot_rows(snapshot_id);
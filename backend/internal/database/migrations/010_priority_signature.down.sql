@@ -0,0 +1,7 @@
+-- 010_priority_signature.down.sql
+-- Rollback priority in the DNS record signature
+-- Fails if MX/SRV records differing only by priority exist; remove them first.
+
+DROP INDEX IF EXISTS idx_dns_records_signature;
+ALTER TABLE dns_records ADD CONSTRAINT dns_records_domain_subdomain_record_type_data_source_key
+    UNIQUE (domain, subdomain, record_type, data, source);
@@ -0,0 +1,9 @@
+-- 010_priority_signature.up.sql
+-- Include priority in the DNS record signature for MX and SRV
+
+-- MX/SRV records with the same data but a different priority are distinct
+ALTER TABLE dns_records DROP CONSTRAINT IF EXISTS dns_records_domain_subdomain_record_type_data_source_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dns_records_signature ON dns_records (
+    domain, subdomain, record_type, data, source,
+    (CASE WHEN record_type IN ('MX', 'SRV') THEN COALESCE(priority, 0) ELSE 0 END)
+);
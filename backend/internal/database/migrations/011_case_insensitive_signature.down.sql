@@ -0,0 +1,5 @@
+-- 011_case_insensitive_signature.down.sql
+-- Rollback case-insensitive hostname data in the DNS record signature
+-- Lowercased data is kept as it is.
+
+DROP INDEX IF EXISTS idx_dns_records_signature_ci;
@@ -0,0 +1,34 @@
+-- 011_case_insensitive_signature.up.sql
+-- Compare hostname record data (CNAME, NS, MX, PTR, SRV) case-insensitively
+
+-- Store existing hostname data lowercased, as the merger does, keeping one
+-- row per case-insensitive signature (active first, then most recently seen)
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'idx_dns_records_signature_ci') THEN
+        DELETE FROM dns_records WHERE id IN (
+            SELECT id FROM (
+                SELECT id, ROW_NUMBER() OVER (
+                    PARTITION BY domain, subdomain, record_type, lower(data), source,
+                        (CASE WHEN record_type IN ('MX', 'SRV') THEN COALESCE(priority, 0) ELSE 0 END)
+                    ORDER BY (status = 'active') DESC, last_seen DESC, updated_at DESC NULLS LAST
+                ) AS n
+                FROM dns_records
+                WHERE record_type IN ('CNAME', 'NS', 'MX', 'PTR', 'SRV')
+            ) ranked
+            WHERE n > 1
+        );
+
+        UPDATE dns_records SET data = lower(data)
+        WHERE record_type IN ('CNAME', 'NS', 'MX', 'PTR', 'SRV') AND data <> lower(data);
+    END IF;
+END $$;
+
+-- Rows differing only in the case of hostname data share a signature
+-- (idx_dns_records_signature is kept: 010 re-creates it on every startup)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dns_records_signature_ci ON dns_records (
+    domain, subdomain, record_type,
+    (CASE WHEN record_type IN ('CNAME', 'NS', 'MX', 'PTR', 'SRV') THEN lower(data) ELSE data END),
+    source,
+    (CASE WHEN record_type IN ('MX', 'SRV') THEN COALESCE(priority, 0) ELSE 0 END)
+);
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// Snapshot copies every domain and DNS record row of a source into
+// merge_snapshots and returns the snapshot ID. Domains are matched on
+// registrar, records on source.
+func (db *DB) Snapshot(ctx context.Context, source string) (id string, domains, records int, err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO merge_snapshots (source) VALUES ($1) RETURNING id
+	`, source).Scan(&id); err != nil {
+		return "", 0, 0, fmt.Errorf("create snapshot: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO merge_snapshot_rows (snapshot_id, table_name, row_data)
+		SELECT $1, 'domains', to_jsonb(d) FROM domains d WHERE registrar = $2
+	`, id, source)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("snapshot domains: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	domains = int(n)
+
+	result, err = tx.ExecContext(ctx, `
+		INSERT INTO merge_snapshot_rows (snapshot_id, table_name, row_data)
+		SELECT $1, 'dns_records', to_jsonb(r) FROM dns_records r WHERE source = $2
+	`, id, source)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("snapshot DNS records: %w", err)
+	}
+	n, _ = result.RowsAffected()
+	records = int(n)
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE merge_snapshots SET domains = $2, dns_records = $3 WHERE id = $1
+	`, id, domains, records); err != nil {
+		return "", 0, 0, fmt.Errorf("update snapshot counts: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", 0, 0, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return id, domains, records, nil
+}
+
+// PruneSnapshots deletes all but the newest keep snapshots of a source
+func (db *DB) PruneSnapshots(ctx context.Context, source string, keep int) (int, error) {
+	result, err := db.ExecContext(ctx, `
+		DELETE FROM merge_snapshots
+		WHERE source = $1 AND id NOT IN (
+			SELECT id FROM merge_snapshots
+			WHERE source = $1
+			ORDER BY created_at DESC
+			LIMIT $2
+		)
+	`, source, keep)
+	if err != nil {
+		return 0, err
+	}
+
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
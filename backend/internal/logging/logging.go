@@ -0,0 +1,26 @@
+// Package logging selects the backend's log output format
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// Setup configures the default slog logger for the given format
+// "text" keeps the standard log package output, with structured fields
+// appended as key=value pairs. "json" writes one JSON object per line to
+// stderr; lines still written with the log package become JSON too, with
+// the formatted line as the message.
+func Setup(format string) error {
+	switch format {
+	case "", "text":
+		return nil
+	case "json":
+		handler := slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{AddSource: true})
+		slog.SetDefault(slog.New(handler))
+		return nil
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+}
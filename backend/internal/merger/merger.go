@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"0xdomainsnapshot/internal/collector"
+	"0xdomainsnapshot/internal/collector/dns"
 	"0xdomainsnapshot/internal/database"
 )
 
@@ -18,6 +19,21 @@ type MergeStats struct {
 	Removed int
 }
 
+// MergeOptions controls a merge operation
+type MergeOptions struct {
+	// SkipRemoval disables marking unseen records as removed. Used for
+	// partial results, where missing records may simply not have been fetched.
+	SkipRemoval bool
+
+	// Account limits marking unseen domains as removed to those collected
+	// from this provider account (see collector.CollectorResult.Account)
+	Account string
+
+	// DryRun computes the stats as usual but rolls the transaction back,
+	// so nothing is written
+	DryRun bool
+}
+
 // Merger handles merging new records with existing database records
 type Merger struct {
 	db *database.DB
@@ -30,8 +46,8 @@ func New(db *database.DB) *Merger {
 
 // MergeDomains merges new domains with existing records
 // - Preserves discovery_date for existing records
-// - Marks missing records as "removed"
-func (m *Merger) MergeDomains(ctx context.Context, source string, domains []collector.Domain) (*MergeStats, error) {
+// - Marks missing records as "removed" (unless opts.SkipRemoval)
+func (m *Merger) MergeDomains(ctx context.Context, source string, domains []collector.Domain, opts MergeOptions) (*MergeStats, error) {
 	stats := &MergeStats{}
 
 	tx, err := m.db.BeginTx(ctx, nil)
@@ -54,6 +70,18 @@ func (m *Merger) MergeDomains(ctx context.Context, source string, domains []coll
 			rawJSON, _ = json.Marshal(d.RawData)
 		}
 
+		var attrJSON []byte
+		if len(d.Attributes) > 0 {
+			attrJSON, _ = json.Marshal(d.Attributes)
+		}
+
+		// Collectors report the provider's status (e.g. a pending
+		// Cloudflare zone); anything collected without one is active
+		status := d.Status
+		if status == "" {
+			status = "active"
+		}
+
 		// Try to find existing record
 		var existingID string
 		err := tx.QueryRowContext(ctx, `
@@ -64,9 +92,9 @@ func (m *Merger) MergeDomains(ctx context.Context, source string, domains []coll
 		if err == sql.ErrNoRows {
 			// New domain - insert
 			_, err = tx.ExecContext(ctx, `
-				INSERT INTO domains (domain, registrar, status, expiry_date, discovery_date, last_seen, raw_data)
-				VALUES ($1, $2, 'active', $3, $4, $4, $5)
-			`, d.Domain, source, d.ExpiryDate, today, rawJSON)
+				INSERT INTO domains (domain, registrar, status, expiry_date, discovery_date, last_seen, last_present_at, raw_data, attributes)
+				VALUES ($1, $2, $7, $3, $4, $4, NOW(), $5, $6)
+			`, d.Domain, source, d.ExpiryDate, today, rawJSON, attrJSON, status)
 			if err != nil {
 				return nil, fmt.Errorf("insert domain %s: %w", d.Domain, err)
 			}
@@ -75,9 +103,9 @@ func (m *Merger) MergeDomains(ctx context.Context, source string, domains []coll
 			// Existing domain - update (preserve discovery_date)
 			_, err = tx.ExecContext(ctx, `
 				UPDATE domains
-				SET status = 'active', expiry_date = $1, last_seen = $2, raw_data = $3, updated_at = NOW()
-				WHERE id = $4
-			`, d.ExpiryDate, today, rawJSON, existingID)
+				SET status = $6, expiry_date = $1, last_seen = $2, last_present_at = NOW(), raw_data = $3, attributes = $4, updated_at = NOW()
+				WHERE id = $5
+			`, d.ExpiryDate, today, rawJSON, attrJSON, existingID, status)
 			if err != nil {
 				return nil, fmt.Errorf("update domain %s: %w", d.Domain, err)
 			}
@@ -88,17 +116,24 @@ func (m *Merger) MergeDomains(ctx context.Context, source string, domains []coll
 	}
 
 	// Mark missing domains from this source as removed
-	result, err := tx.ExecContext(ctx, `
-		UPDATE domains
-		SET status = 'removed', updated_at = NOW()
-		WHERE registrar = $1 AND status = 'active' AND last_seen < $2
-	`, source, today)
-	if err != nil {
-		return nil, fmt.Errorf("mark removed domains: %w", err)
+	if !opts.SkipRemoval {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE domains
+			SET status = 'removed', updated_at = NOW()
+			WHERE registrar = $1 AND status <> 'removed' AND last_seen < $2
+			  AND COALESCE(attributes->>'account', '') = $3
+		`, source, today, opts.Account)
+		if err != nil {
+			return nil, fmt.Errorf("mark removed domains: %w", err)
+		}
+
+		removed, _ := result.RowsAffected()
+		stats.Removed = int(removed)
 	}
 
-	removed, _ := result.RowsAffected()
-	stats.Removed = int(removed)
+	if opts.DryRun {
+		return stats, nil // Rolled back by the deferred Rollback
+	}
 
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("commit transaction: %w", err)
@@ -109,9 +144,11 @@ func (m *Merger) MergeDomains(ctx context.Context, source string, domains []coll
 
 // MergeDNSRecords merges new DNS records with existing records
 // - Uses signature (domain, subdomain, type, data, source) for matching
+// - Adds priority to the signature for MX/SRV, where it distinguishes records
+// - Compares data case-insensitively for hostname-valued types
 // - Preserves discovery_date for existing records
-// - Marks missing records as "removed"
-func (m *Merger) MergeDNSRecords(ctx context.Context, source string, records []collector.DNSRecord) (*MergeStats, error) {
+// - Marks missing records as "removed" (unless opts.SkipRemoval)
+func (m *Merger) MergeDNSRecords(ctx context.Context, source string, records []collector.DNSRecord, opts MergeOptions) (*MergeStats, error) {
 	stats := &MergeStats{}
 
 	tx, err := m.db.BeginTx(ctx, nil)
@@ -134,20 +171,38 @@ func (m *Merger) MergeDNSRecords(ctx context.Context, source string, records []c
 			rawJSON, _ = json.Marshal(r.RawData)
 		}
 
+		// Hostname-valued data is compared case-insensitively so a
+		// provider changing a CNAME's casing doesn't cause churn
+		r.Data = dns.NormalizeRecordData(r.RecordType, r.Data)
+
+		var attrJSON []byte
+		if len(r.Attributes) > 0 {
+			attrJSON, _ = json.Marshal(r.Attributes)
+		}
+		dataMatch := "data = $4"
+		if dns.IsHostnameRecordType(r.RecordType) {
+			dataMatch = "lower(data) = $4"
+		}
+		args := []interface{}{r.Domain, r.Subdomain, r.RecordType, r.Data, source}
+		if dns.HasSignificantPriority(r.RecordType) {
+			dataMatch += " AND COALESCE(priority, 0) = $6"
+			args = append(args, r.Priority)
+		}
+
 		// Try to find existing record by signature
 		var existingID string
 		err := tx.QueryRowContext(ctx, `
 			SELECT id FROM dns_records
-			WHERE domain = $1 AND subdomain = $2 AND record_type = $3 AND data = $4 AND source = $5
-		`, r.Domain, r.Subdomain, r.RecordType, r.Data, source).Scan(&existingID)
+			WHERE domain = $1 AND subdomain = $2 AND record_type = $3 AND `+dataMatch+` AND source = $5
+		`, args...).Scan(&existingID)
 
 		if err == sql.ErrNoRows {
 			// New record - insert
 			_, err = tx.ExecContext(ctx, `
 				INSERT INTO dns_records
-				(domain, subdomain, record_type, data, ttl, priority, source, status, discovery_date, last_seen, raw_data)
-				VALUES ($1, $2, $3, $4, $5, $6, $7, 'active', $8, $8, $9)
-			`, r.Domain, r.Subdomain, r.RecordType, r.Data, r.TTL, r.Priority, source, today, rawJSON)
+				(domain, subdomain, record_type, data, ttl, priority, proxied, source, status, discovery_date, last_seen, last_present_at, raw_data, attributes)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'active', $9, $9, NOW(), $10, $11)
+			`, r.Domain, r.Subdomain, r.RecordType, r.Data, r.TTL, r.Priority, r.Proxied, source, today, rawJSON, attrJSON)
 			if err != nil {
 				return nil, fmt.Errorf("insert record %s.%s: %w", r.Subdomain, r.Domain, err)
 			}
@@ -156,9 +211,9 @@ func (m *Merger) MergeDNSRecords(ctx context.Context, source string, records []c
 			// Existing record - update (preserve discovery_date)
 			_, err = tx.ExecContext(ctx, `
 				UPDATE dns_records
-				SET status = 'active', ttl = $1, priority = $2, last_seen = $3, raw_data = $4, updated_at = NOW()
-				WHERE id = $5
-			`, r.TTL, r.Priority, today, rawJSON, existingID)
+				SET status = 'active', data = $1, ttl = $2, priority = $3, proxied = $4, last_seen = $5, last_present_at = NOW(), raw_data = $6, attributes = $7, updated_at = NOW()
+				WHERE id = $8
+			`, r.Data, r.TTL, r.Priority, r.Proxied, today, rawJSON, attrJSON, existingID)
 			if err != nil {
 				return nil, fmt.Errorf("update record %s.%s: %w", r.Subdomain, r.Domain, err)
 			}
@@ -170,18 +225,24 @@ func (m *Merger) MergeDNSRecords(ctx context.Context, source string, records []c
 
 	// Mark missing records from this source as removed
 	// Only for domains we actually checked
-	for domain := range seenDomains {
-		result, err := tx.ExecContext(ctx, `
-			UPDATE dns_records
-			SET status = 'removed', updated_at = NOW()
-			WHERE source = $1 AND domain = $2 AND status = 'active' AND last_seen < $3
-		`, source, domain, today)
-		if err != nil {
-			return nil, fmt.Errorf("mark removed records for %s: %w", domain, err)
+	if !opts.SkipRemoval {
+		for domain := range seenDomains {
+			result, err := tx.ExecContext(ctx, `
+				UPDATE dns_records
+				SET status = 'removed', updated_at = NOW()
+				WHERE source = $1 AND domain = $2 AND status = 'active' AND last_seen < $3
+			`, source, domain, today)
+			if err != nil {
+				return nil, fmt.Errorf("mark removed records for %s: %w", domain, err)
+			}
+
+			removed, _ := result.RowsAffected()
+			stats.Removed += int(removed)
 		}
+	}
 
-		removed, _ := result.RowsAffected()
-		stats.Removed += int(removed)
+	if opts.DryRun {
+		return stats, nil // Rolled back by the deferred Rollback
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -0,0 +1,262 @@
+package merger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"0xdomainsnapshot/internal/collector"
+	"0xdomainsnapshot/internal/config"
+	"0xdomainsnapshot/internal/database"
+)
+
+// testDomain is the domain merged records belong to
+const testDomain = "merger-test.example"
+
+// newTestMerger connects to TEST_DATABASE_URL (tests are skipped without
+// it) and returns a merger with a source name unique to the test, whose
+// rows are deleted afterwards
+func newTestMerger(t *testing.T) (*Merger, *database.DB, string) {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := database.New(config.DatabaseConfig{URL: url, MaxConnections: 5, MaxIdle: 1})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if err := db.RunMigrations(context.Background()); err != nil {
+		db.Close()
+		t.Fatalf("migrate: %v", err)
+	}
+
+	source := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM dns_records WHERE source = $1`, source)
+		db.Close()
+	})
+	return New(db), db, source
+}
+
+func record(recordType, data string, priority int) collector.DNSRecord {
+	return collector.DNSRecord{
+		Domain:     testDomain,
+		Subdomain:  "www",
+		RecordType: recordType,
+		Data:       data,
+		TTL:        300,
+		Priority:   priority,
+	}
+}
+
+// storedRecord is a row of the test source
+type storedRecord struct {
+	recordType string
+	data       string
+	priority   int
+}
+
+func storedRecords(t *testing.T, db *database.DB, source string) []storedRecord {
+	t.Helper()
+
+	rows, err := db.Query(`
+		SELECT record_type, data, COALESCE(priority, 0) FROM dns_records
+		WHERE source = $1 ORDER BY record_type, data, priority
+	`, source)
+	if err != nil {
+		t.Fatalf("query records: %v", err)
+	}
+	defer rows.Close()
+
+	var records []storedRecord
+	for rows.Next() {
+		var r storedRecord
+		if err := rows.Scan(&r.recordType, &r.data, &r.priority); err != nil {
+			t.Fatalf("scan record: %v", err)
+		}
+		records = append(records, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func merge(t *testing.T, m *Merger, source string, records ...collector.DNSRecord) *MergeStats {
+	t.Helper()
+	stats, err := m.MergeDNSRecords(context.Background(), source, records, MergeOptions{})
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	return stats
+}
+
+func TestMergeCNAMECaseChangeIsNoChurn(t *testing.T) {
+	m, db, source := newTestMerger(t)
+
+	merge(t, m, source, record("CNAME", "Target.Example.COM", 0))
+	stats := merge(t, m, source, record("CNAME", "target.example.com", 0))
+
+	if stats.Added != 0 || stats.Updated != 1 {
+		t.Errorf("second merge: added=%d updated=%d, want added=0 updated=1", stats.Added, stats.Updated)
+	}
+	want := []storedRecord{{"CNAME", "target.example.com", 0}}
+	if got := storedRecords(t, db, source); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("records = %v, want %v", got, want)
+	}
+}
+
+func TestMergeTXTCaseChangeIsNewRecord(t *testing.T) {
+	m, db, source := newTestMerger(t)
+
+	merge(t, m, source, record("TXT", "v=DKIM1; p=abcDEF", 0))
+	stats := merge(t, m, source, record("TXT", "v=DKIM1; p=ABCdef", 0))
+
+	if stats.Added != 1 || stats.Updated != 0 {
+		t.Errorf("second merge: added=%d updated=%d, want added=1 updated=0", stats.Added, stats.Updated)
+	}
+	want := []storedRecord{{"TXT", "v=DKIM1; p=ABCdef", 0}, {"TXT", "v=DKIM1; p=abcDEF", 0}}
+	if got := storedRecords(t, db, source); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("records = %v, want %v", got, want)
+	}
+}
+
+func TestSignatureIndexIgnoresHostnameCase(t *testing.T) {
+	_, db, source := newTestMerger(t)
+
+	insert := `
+		INSERT INTO dns_records (domain, subdomain, record_type, data, source)
+		VALUES ($1, 'www', 'CNAME', $2, $3)
+	`
+	if _, err := db.Exec(insert, testDomain, "target.example.com", source); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if _, err := db.Exec(insert, testDomain, "TARGET.example.com", source); err == nil {
+		t.Error("inserting a CNAME differing only in case succeeded, want a unique violation")
+	}
+}
+
+func TestMergeKeepsPrioritiesApart(t *testing.T) {
+	m, db, source := newTestMerger(t)
+
+	stats := merge(t, m, source,
+		record("MX", "mail.example.com", 10),
+		record("MX", "mail.example.com", 20),
+		record("SRV", "5 5060 sip.example.com", 10),
+		record("SRV", "5 5060 sip.example.com", 20),
+	)
+	if stats.Added != 4 || stats.Updated != 0 {
+		t.Errorf("first merge: added=%d updated=%d, want added=4 updated=0", stats.Added, stats.Updated)
+	}
+
+	stats = merge(t, m, source, record("MX", "mail.example.com", 20), record("SRV", "5 5060 sip.example.com", 10))
+	if stats.Added != 0 || stats.Updated != 2 {
+		t.Errorf("second merge: added=%d updated=%d, want added=0 updated=2", stats.Added, stats.Updated)
+	}
+
+	want := []storedRecord{
+		{"MX", "mail.example.com", 10},
+		{"MX", "mail.example.com", 20},
+		{"SRV", "5 5060 sip.example.com", 10},
+		{"SRV", "5 5060 sip.example.com", 20},
+	}
+	if got := storedRecords(t, db, source); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("records = %v, want %v", got, want)
+	}
+}
+
+func TestMergeIgnoresPriorityOfOtherTypes(t *testing.T) {
+	m, db, source := newTestMerger(t)
+
+	merge(t, m, source, record("A", "192.0.2.1", 0), record("CNAME", "target.example.com", 0))
+	stats := merge(t, m, source, record("A", "192.0.2.1", 5), record("CNAME", "target.example.com", 10))
+
+	if stats.Added != 0 || stats.Updated != 2 {
+		t.Errorf("second merge: added=%d updated=%d, want added=0 updated=2", stats.Added, stats.Updated)
+	}
+	want := []storedRecord{{"A", "192.0.2.1", 5}, {"CNAME", "target.example.com", 10}}
+	if got := storedRecords(t, db, source); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("records = %v, want %v", got, want)
+	}
+}
+
+func TestNormalizeTXTRecords(t *testing.T) {
+	m, db, source := newTestMerger(t)
+
+	insert := `
+		INSERT INTO dns_records (domain, subdomain, record_type, data, source, status, last_seen)
+		VALUES ($1, $2, 'TXT', $3, $4, $5, $6)
+	`
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	rows := []struct {
+		subdomain, data, status string
+		lastSeen                time.Time
+	}{
+		// Quoted only: rewritten
+		{"_dmarc", `"v=DMARC1\059 p=reject"`, "active", today},
+		// Quoted and canonical: the active, more recent quoted row is kept
+		{"sel._domainkey", `"v=DKIM1; k=rsa; " "p=MIIBIjANBgkq"`, "active", today},
+		{"sel._domainkey", "v=DKIM1; k=rsa; p=MIIBIjANBgkq", "removed", today.AddDate(0, 0, -3)},
+		// Canonical already: left alone
+		{"", "v=spf1 -all", "active", today},
+	}
+	for _, r := range rows {
+		if _, err := db.Exec(insert, testDomain, r.subdomain, r.data, source, r.status, r.lastSeen); err != nil {
+			t.Fatalf("insert %s: %v", r.subdomain, err)
+		}
+	}
+
+	changed, err := m.NormalizeTXTRecords(context.Background())
+	if err != nil {
+		t.Fatalf("NormalizeTXTRecords: %v", err)
+	}
+	if changed < 2 {
+		t.Errorf("changed = %d, want at least 2", changed)
+	}
+
+	got, err := db.Query(`
+		SELECT subdomain, data, status FROM dns_records WHERE source = $1 ORDER BY subdomain
+	`, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Close()
+
+	want := []string{
+		"|v=spf1 -all|active",
+		"_dmarc|v=DMARC1; p=reject|active",
+		"sel._domainkey|v=DKIM1; k=rsa; p=MIIBIjANBgkq|active",
+	}
+	var records []string
+	for got.Next() {
+		var subdomain, data, status string
+		if err := got.Scan(&subdomain, &data, &status); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, subdomain+"|"+data+"|"+status)
+	}
+	if fmt.Sprint(records) != fmt.Sprint(want) {
+		t.Errorf("records = %q, want %q", records, want)
+	}
+
+	// The quoted strings' lengths are kept for the length check
+	var lengths string
+	db.QueryRow(`
+		SELECT attributes->>'txt_strings' FROM dns_records WHERE source = $1 AND subdomain = 'sel._domainkey'
+	`, source).Scan(&lengths)
+	if lengths != "16,14" {
+		t.Errorf("txt_strings = %q, want %q", lengths, "16,14")
+	}
+
+	// Nothing is left to rewrite for the test source
+	var quoted int
+	db.QueryRow(`SELECT COUNT(*) FROM dns_records WHERE source = $1 AND data LIKE '"%'`, source).Scan(&quoted)
+	if quoted != 0 {
+		t.Errorf("%d quoted rows left, want 0", quoted)
+	}
+}
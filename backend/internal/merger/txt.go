@@ -0,0 +1,125 @@
+package merger
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"0xdomainsnapshot/internal/collector/dns"
+)
+
+// storedTXT is a TXT/SPF row whose data is not in canonical form
+type storedTXT struct {
+	id, domain, subdomain, recordType, data, source string
+}
+
+// NormalizeTXTRecords rewrites stored TXT and SPF data that is still in its
+// quoted form ("v=DKIM1; " "p=...") to the canonical form merges store
+// (see dns.NormalizeTXTData), so existing records match what providers
+// return. The quoted strings' lengths are kept in the dns.AttrTXTStrings
+// attribute for the length check. Where the rewritten row duplicates a
+// stored canonical one, the
+// active and most recently seen row is kept with the earliest discovery
+// date. Safe to run on every startup; returns the number of rows rewritten
+// or deleted.
+func (m *Merger) NormalizeTXTRecords(ctx context.Context) (int, error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, domain, subdomain, record_type, data, source FROM dns_records
+		WHERE record_type IN ('TXT', 'SPF') AND ltrim(data) LIKE '"%'
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("query quoted TXT records: %w", err)
+	}
+	var quoted []storedTXT
+	for rows.Next() {
+		var r storedTXT
+		if err := rows.Scan(&r.id, &r.domain, &r.subdomain, &r.recordType, &r.data, &r.source); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		quoted = append(quoted, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, r := range quoted {
+		data := dns.NormalizeTXTData(r.data)
+		if data == r.data {
+			continue
+		}
+		lengths := dns.TXTStringLengths(r.data)
+
+		var otherID string
+		err := tx.QueryRowContext(ctx, `
+			SELECT id FROM dns_records
+			WHERE domain = $1 AND subdomain = $2 AND record_type = $3 AND data = $4 AND source = $5
+		`, r.domain, r.subdomain, r.recordType, data, r.source).Scan(&otherID)
+
+		switch {
+		case err == sql.ErrNoRows:
+			err = setCanonical(ctx, tx, r.id, data, lengths)
+		case err == nil:
+			err = keepPreferred(ctx, tx, r.id, otherID, data, lengths)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("normalize TXT record %s.%s: %w", r.subdomain, r.domain, err)
+		}
+		changed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+	return changed, nil
+}
+
+// keepPreferred merges two rows of the same record into the one that is
+// active and most recently seen, deleting the other
+func keepPreferred(ctx context.Context, tx *sql.Tx, quotedID, canonicalID, data, lengths string) error {
+	var keep, drop string
+	err := tx.QueryRowContext(ctx, `
+		SELECT id FROM dns_records WHERE id IN ($1, $2)
+		ORDER BY (status = 'active') DESC, last_seen DESC, updated_at DESC NULLS LAST
+		LIMIT 1
+	`, quotedID, canonicalID).Scan(&keep)
+	if err != nil {
+		return err
+	}
+	drop = canonicalID
+	if keep == canonicalID {
+		drop = quotedID
+	}
+
+	// The dropped row may have been discovered first
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE dns_records SET discovery_date = LEAST(discovery_date,
+			(SELECT discovery_date FROM dns_records WHERE id = $2))
+		WHERE id = $1
+	`, keep, drop); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dns_records WHERE id = $1`, drop); err != nil {
+		return err
+	}
+	return setCanonical(ctx, tx, keep, data, lengths)
+}
+
+// setCanonical stores the canonical data of a row, with the string lengths
+// of its quoted data unless the row already has them
+func setCanonical(ctx context.Context, tx *sql.Tx, id, data, lengths string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE dns_records
+		SET data = $1, attributes = jsonb_build_object($2::text, $3::text) || COALESCE(attributes, '{}'::jsonb), updated_at = NOW()
+		WHERE id = $4
+	`, data, dns.AttrTXTStrings, lengths, id)
+	return err
+}
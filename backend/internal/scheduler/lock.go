@@ -3,6 +3,8 @@ package scheduler
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -42,7 +44,7 @@ func (s *SyncLock) getLock(collectorName string) *sync.Mutex {
 // - syncID: ID of the sync_status record (use for Release)
 // - acquired: true if lock was acquired, false if already running
 // - error: any error that occurred
-func (s *SyncLock) TryAcquire(ctx context.Context, collectorName, serviceType, triggerType string) (string, bool, error) {
+func (s *SyncLock) TryAcquire(ctx context.Context, collectorName, serviceType, triggerType string, labels map[string]string) (string, bool, error) {
 	lock := s.getLock(collectorName)
 
 	// Try to acquire in-memory lock (non-blocking)
@@ -67,13 +69,19 @@ func (s *SyncLock) TryAcquire(ctx context.Context, collectorName, serviceType, t
 		return "", false, fmt.Errorf("check running sync: %w", err)
 	}
 
+	// Serialize labels to JSON
+	var labelsJSON []byte
+	if len(labels) > 0 {
+		labelsJSON, _ = json.Marshal(labels)
+	}
+
 	// Create new sync record
 	var syncID string
 	err = s.db.QueryRowContext(ctx, `
-		INSERT INTO sync_status (collector_name, service_type, status, trigger_type, started_at)
-		VALUES ($1, $2, 'running', $3, NOW())
+		INSERT INTO sync_status (collector_name, service_type, status, trigger_type, started_at, labels)
+		VALUES ($1, $2, 'running', $3, NOW(), $4)
 		RETURNING id
-	`, collectorName, serviceType, triggerType).Scan(&syncID)
+	`, collectorName, serviceType, triggerType, labelsJSON).Scan(&syncID)
 
 	if err != nil {
 		lock.Unlock()
@@ -84,14 +92,15 @@ func (s *SyncLock) TryAcquire(ctx context.Context, collectorName, serviceType, t
 }
 
 // Release releases the lock and updates sync status
+// The run is recorded as "completed", "failed", or "cancelled" when the
+// error wraps ErrSyncCancelled.
 func (s *SyncLock) Release(ctx context.Context, collectorName, syncID string, stats SyncReleaseStats, syncError error) error {
 	lock := s.getLock(collectorName)
 	defer lock.Unlock()
 
-	status := "completed"
+	status := releaseStatus(syncError)
 	var errMsg *string
 	if syncError != nil {
-		status = "failed"
 		msg := syncError.Error()
 		errMsg = &msg
 	}
@@ -112,6 +121,18 @@ func (s *SyncLock) Release(ctx context.Context, collectorName, syncID string, st
 	return nil
 }
 
+// releaseStatus returns the sync_status status of a run ending with err
+func releaseStatus(err error) string {
+	switch {
+	case err == nil:
+		return "completed"
+	case errors.Is(err, ErrSyncCancelled):
+		return "cancelled"
+	default:
+		return "failed"
+	}
+}
+
 // SyncReleaseStats holds stats for releasing a sync lock
 type SyncReleaseStats struct {
 	Found   int
@@ -132,16 +153,31 @@ func (s *SyncLock) IsRunning(ctx context.Context, collectorName string) (bool, e
 }
 
 // GetStatus returns the latest status for all collectors
-func (s *SyncLock) GetStatus(ctx context.Context) ([]CollectorStatusInfo, error) {
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT DISTINCT ON (collector_name)
-			collector_name, service_type, status, trigger_type,
-			started_at, completed_at,
-			records_found, records_added, records_updated, records_removed,
-			error_message
-		FROM sync_status
-		ORDER BY collector_name, started_at DESC
-	`)
+// If labels is non-empty, only collectors whose latest run carries all the
+// given labels are returned.
+func (s *SyncLock) GetStatus(ctx context.Context, labels map[string]string) ([]CollectorStatusInfo, error) {
+	query := `
+		SELECT * FROM (
+			SELECT DISTINCT ON (collector_name)
+				collector_name, service_type, status, trigger_type,
+				started_at, completed_at,
+				records_found, records_added, records_updated, records_removed,
+				error_message, labels
+			FROM sync_status
+			ORDER BY collector_name, started_at DESC
+		) latest
+	`
+	args := []interface{}{}
+
+	if len(labels) > 0 {
+		labelsJSON, _ := json.Marshal(labels)
+		query += " WHERE labels @> $1"
+		args = append(args, labelsJSON)
+	}
+
+	query += " ORDER BY collector_name"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -153,17 +189,22 @@ func (s *SyncLock) GetStatus(ctx context.Context) ([]CollectorStatusInfo, error)
 		var completedAt sql.NullTime
 		var errMsg sql.NullString
 		var found, added, updated, removed sql.NullInt64
+		var labelsJSON []byte
 
 		err := rows.Scan(
 			&s.Name, &s.ServiceType, &s.Status, &s.TriggerType,
 			&s.StartedAt, &completedAt,
 			&found, &added, &updated, &removed,
-			&errMsg,
+			&errMsg, &labelsJSON,
 		)
 		if err != nil {
 			return nil, err
 		}
 
+		if labelsJSON != nil {
+			json.Unmarshal(labelsJSON, &s.Labels)
+		}
+
 		if completedAt.Valid {
 			s.CompletedAt = &completedAt.Time
 		}
@@ -195,12 +236,13 @@ func (s *SyncLock) GetCollectorStatus(ctx context.Context, collectorName string)
 	var completedAt sql.NullTime
 	var errMsg sql.NullString
 	var found, added, updated, removed sql.NullInt64
+	var labelsJSON []byte
 
 	err := s.db.QueryRowContext(ctx, `
 		SELECT collector_name, service_type, status, trigger_type,
 		       started_at, completed_at,
 		       records_found, records_added, records_updated, records_removed,
-		       error_message
+		       error_message, labels
 		FROM sync_status
 		WHERE collector_name = $1
 		ORDER BY started_at DESC
@@ -209,7 +251,7 @@ func (s *SyncLock) GetCollectorStatus(ctx context.Context, collectorName string)
 		&status.Name, &status.ServiceType, &status.Status, &status.TriggerType,
 		&status.StartedAt, &completedAt,
 		&found, &added, &updated, &removed,
-		&errMsg,
+		&errMsg, &labelsJSON,
 	)
 
 	if err == sql.ErrNoRows {
@@ -219,6 +261,10 @@ func (s *SyncLock) GetCollectorStatus(ctx context.Context, collectorName string)
 		return nil, err
 	}
 
+	if labelsJSON != nil {
+		json.Unmarshal(labelsJSON, &status.Labels)
+	}
+
 	if completedAt.Valid {
 		status.CompletedAt = &completedAt.Time
 	}
@@ -243,17 +289,18 @@ func (s *SyncLock) GetCollectorStatus(ctx context.Context, collectorName string)
 
 // CollectorStatusInfo holds status information for a collector
 type CollectorStatusInfo struct {
-	Name           string     `json:"name"`
-	ServiceType    string     `json:"service_type"`
-	Status         string     `json:"status"`
-	TriggerType    string     `json:"trigger_type"`
-	StartedAt      time.Time  `json:"started_at"`
-	CompletedAt    *time.Time `json:"completed_at,omitempty"`
-	RecordsFound   int        `json:"records_found"`
-	RecordsAdded   int        `json:"records_added"`
-	RecordsUpdated int        `json:"records_updated"`
-	RecordsRemoved int        `json:"records_removed"`
-	ErrorMessage   string     `json:"error_message,omitempty"`
+	Name           string            `json:"name"`
+	ServiceType    string            `json:"service_type"`
+	Status         string            `json:"status"`
+	TriggerType    string            `json:"trigger_type"`
+	StartedAt      time.Time         `json:"started_at"`
+	CompletedAt    *time.Time        `json:"completed_at,omitempty"`
+	RecordsFound   int               `json:"records_found"`
+	RecordsAdded   int               `json:"records_added"`
+	RecordsUpdated int               `json:"records_updated"`
+	RecordsRemoved int               `json:"records_removed"`
+	ErrorMessage   string            `json:"error_message,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
 }
 
 // CleanupStale marks any stale "running" records as failed
@@ -274,3 +321,79 @@ func (s *SyncLock) CleanupStale(ctx context.Context, maxAge time.Duration) (int,
 	affected, _ := result.RowsAffected()
 	return int(affected), nil
 }
+
+// GetStatsHistory returns per-day sync statistics for the last N days
+// Days without any sync are zero-filled so the series has no gaps.
+// An empty collectorName aggregates across all collectors; labels further
+// restrict the runs to those carrying all given labels.
+func (s *SyncLock) GetStatsHistory(ctx context.Context, collectorName string, days int, labels map[string]string) ([]DailySyncStats, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, -(days - 1))
+
+	query := `
+		SELECT date_trunc('day', started_at AT TIME ZONE 'UTC') AS day,
+		       COUNT(*),
+		       COALESCE(SUM(records_found), 0), COALESCE(SUM(records_added), 0),
+		       COALESCE(SUM(records_updated), 0), COALESCE(SUM(records_removed), 0)
+		FROM sync_status
+		WHERE started_at >= $1 AND status <> 'running'
+	`
+	args := []interface{}{start}
+
+	if collectorName != "" {
+		args = append(args, collectorName)
+		query += fmt.Sprintf(" AND collector_name = $%d", len(args))
+	}
+	if len(labels) > 0 {
+		labelsJSON, _ := json.Marshal(labels)
+		args = append(args, labelsJSON)
+		query += fmt.Sprintf(" AND labels @> $%d", len(args))
+	}
+
+	query += " GROUP BY day ORDER BY day"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byDay := make(map[string]DailySyncStats)
+	for rows.Next() {
+		var day time.Time
+		var d DailySyncStats
+
+		if err := rows.Scan(&day, &d.Syncs, &d.RecordsFound, &d.RecordsAdded, &d.RecordsUpdated, &d.RecordsRemoved); err != nil {
+			return nil, err
+		}
+
+		d.Date = day.Format("2006-01-02")
+		byDay[d.Date] = d
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Zero-fill days without syncs
+	history := make([]DailySyncStats, 0, days)
+	for day := start; !day.After(today); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		if d, ok := byDay[date]; ok {
+			history = append(history, d)
+		} else {
+			history = append(history, DailySyncStats{Date: date})
+		}
+	}
+
+	return history, nil
+}
+
+// DailySyncStats holds aggregated sync statistics for a single day
+type DailySyncStats struct {
+	Date           string `json:"date"`
+	Syncs          int    `json:"syncs"`
+	RecordsFound   int    `json:"records_found"`
+	RecordsAdded   int    `json:"records_added"`
+	RecordsUpdated int    `json:"records_updated"`
+	RecordsRemoved int    `json:"records_removed"`
+}
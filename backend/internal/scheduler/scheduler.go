@@ -2,8 +2,10 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math/rand"
 	"sync"
 	"time"
 
@@ -23,9 +25,27 @@ type Scheduler struct {
 	lock      *SyncLock
 	cfg       config.SchedulerConfig
 	jobs      map[string]cron.EntryID
+	running   map[string]context.CancelCauseFunc // Cancels the collector's run in progress
+	paused    bool
+	stop      chan struct{} // Closed when Start's context ends
 	mu        sync.Mutex
 }
 
+// ErrSyncCancelled is the cause of a run stopped through CancelSync; such
+// runs are recorded with status "cancelled"
+var ErrSyncCancelled = errors.New("sync cancelled by operator")
+
+// ErrSyncTimedOut is the error of a run stopped by MaxRunDuration; such
+// runs are recorded as failed
+var ErrSyncTimedOut = errors.New("sync timed out")
+
+// ErrCollectorNotFound is returned for a collector name not registered
+var ErrCollectorNotFound = errors.New("collector not found")
+
+// ErrNotRunning is returned by CancelSync when the collector has no run in
+// progress on this instance
+var ErrNotRunning = errors.New("collector is not running")
+
 // New creates a new Scheduler
 func New(
 	registry *collector.Registry,
@@ -42,6 +62,8 @@ func New(
 		lock:      lock,
 		cfg:       cfg,
 		jobs:      make(map[string]cron.EntryID),
+		running:   make(map[string]context.CancelCauseFunc),
+		stop:      make(chan struct{}),
 	}
 }
 
@@ -50,13 +72,13 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	// Cleanup any stale locks from previous runs
 	stale, err := s.lock.CleanupStale(ctx, 2*time.Hour)
 	if err != nil {
-		log.Printf("[Scheduler] Warning: failed to cleanup stale locks: %v", err)
+		slog.Warn("Cleaning up stale locks failed", "error", err)
 	} else if stale > 0 {
-		log.Printf("[Scheduler] Cleaned up %d stale sync records", stale)
+		slog.Info("Cleaned up stale sync records", "count", stale)
 	}
 
 	if !s.cfg.Enabled {
-		log.Println("[Scheduler] Scheduler disabled")
+		slog.Info("Scheduler disabled")
 		return nil
 	}
 
@@ -64,7 +86,7 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	if s.cfg.DNSCron != "" {
 		for _, c := range s.registry.GetByType(collector.CollectorTypeDNSRecords) {
 			if err := s.scheduleCollector(c, s.cfg.DNSCron); err != nil {
-				log.Printf("[Scheduler] Warning: failed to schedule %s: %v", c.Name(), err)
+				slog.Warn("Scheduling collector failed", "collector", c.Name(), "error", err)
 			}
 		}
 	}
@@ -73,27 +95,51 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	if s.cfg.DomainsCron != "" && s.cfg.DomainsCron != s.cfg.DNSCron {
 		for _, c := range s.registry.GetByType(collector.CollectorTypeDomains) {
 			if err := s.scheduleCollector(c, s.cfg.DomainsCron); err != nil {
-				log.Printf("[Scheduler] Warning: failed to schedule %s: %v", c.Name(), err)
+				slog.Warn("Scheduling collector failed", "collector", c.Name(), "error", err)
 			}
 		}
 	}
 
-	s.cron.Start()
-	log.Printf("[Scheduler] Started with %d scheduled jobs", len(s.jobs))
+	// Schedule the domain expiry check
+	if s.cfg.ExpiryCron != "" {
+		if err := s.scheduleExpiryCheck(s.cfg.ExpiryCron); err != nil {
+			slog.Warn("Scheduling expiry check failed", "error", err)
+		}
+	}
+
+	// Restore the paused state from before the restart
+	paused, err := loadPaused(ctx, s.lock.db)
+	if err != nil {
+		slog.Warn("Loading paused state failed", "error", err)
+	}
+
+	s.mu.Lock()
+	s.paused = paused
+	if !paused {
+		s.cron.Start()
+	}
+	s.mu.Unlock()
+
+	if paused {
+		slog.Info("Scheduler started paused, resume via POST /api/v1/scheduler/resume", "jobs", len(s.jobs))
+	} else {
+		slog.Info("Scheduler started", "jobs", len(s.jobs))
+	}
 
 	// List scheduled jobs
 	for name, entryID := range s.jobs {
 		entry := s.cron.Entry(entryID)
-		log.Printf("[Scheduler] %s: next run at %v", name, entry.Next)
+		slog.Info("Next scheduled run", "job", name, "next_run", entry.Next)
 	}
 
 	// Wait for context cancellation
 	<-ctx.Done()
 
-	log.Println("[Scheduler] Stopping...")
+	slog.Info("Scheduler stopping")
+	close(s.stop)
 	cronCtx := s.cron.Stop()
 	<-cronCtx.Done()
-	log.Println("[Scheduler] Stopped")
+	slog.Info("Scheduler stopped")
 
 	return nil
 }
@@ -109,7 +155,9 @@ func (s *Scheduler) scheduleCollector(c collector.Collector, cronExpr string) er
 	}
 
 	entryID, err := s.cron.AddFunc(cronExpr, func() {
-		s.runCollector(context.Background(), c, "scheduled")
+		if s.waitJitter(c.Name()) {
+			s.runCollector(context.Background(), c, "scheduled")
+		}
 	})
 
 	if err != nil {
@@ -117,29 +165,110 @@ func (s *Scheduler) scheduleCollector(c collector.Collector, cronExpr string) er
 	}
 
 	s.jobs[c.Name()] = entryID
-	log.Printf("[Scheduler] Scheduled %s with cron: %s", c.Name(), cronExpr)
+	slog.Info("Scheduled collector", "collector", c.Name(), "cron", cronExpr)
 
 	return nil
 }
 
+// expiryCheckJob is the scheduled job name of the domain expiry check
+const expiryCheckJob = "expiry_check"
+
+// scheduleExpiryCheck adds the domain expiry check to the cron scheduler
+func (s *Scheduler) scheduleExpiryCheck(cronExpr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entryID, err := s.cron.AddFunc(cronExpr, func() {
+		s.checkExpiry(context.Background())
+	})
+	if err != nil {
+		return fmt.Errorf("add cron job: %w", err)
+	}
+
+	s.jobs[expiryCheckJob] = entryID
+	slog.Info("Scheduled expiry check", "job", expiryCheckJob, "cron", cronExpr)
+
+	return nil
+}
+
+// checkExpiry logs one warning listing the active domains that expire
+// within the configured window (removed domains are not included)
+func (s *Scheduler) checkExpiry(ctx context.Context) {
+	domains, err := s.syncSvc.GetExpiringDomains(ctx, s.cfg.ExpiryWarning)
+	if err != nil {
+		slog.Error("Expiry check failed", "error", err)
+		return
+	}
+
+	if len(domains) == 0 {
+		slog.Info("Expiry check, no domains expiring", "within", s.cfg.ExpiryWarning)
+		return
+	}
+
+	names := make([]string, 0, len(domains))
+	for _, d := range domains {
+		names = append(names, fmt.Sprintf("%v (%v, %v)", d["domain"], d["expiry_date"], d["registrar"]))
+	}
+	slog.Warn("Domains expiring soon", "count", len(domains), "within", s.cfg.ExpiryWarning, "domains", names)
+}
+
+// randomJitter returns a random delay within the configured jitter window
+func (s *Scheduler) randomJitter() time.Duration {
+	if s.cfg.Jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(s.cfg.Jitter)))
+}
+
+// waitJitter delays a scheduled run by a random jitter (see randomJitter)
+// Returns false when the run should be skipped: the scheduler stopped
+// during the delay, or was paused by the time it ended.
+func (s *Scheduler) waitJitter(collectorName string) bool {
+	if delay := s.randomJitter(); delay > 0 {
+		slog.Info("Delaying scheduled run", "collector", collectorName,
+			"delay", delay, "fires_at", time.Now().Add(delay).Format(time.RFC3339))
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-s.stop:
+			slog.Info("Skipping delayed run, scheduler stopping", "collector", collectorName)
+			return false
+		}
+	}
+
+	if s.IsPaused() {
+		slog.Info("Skipping delayed run, scheduler paused", "collector", collectorName)
+		return false
+	}
+	return true
+}
+
 // runCollector runs a collector with locking
 func (s *Scheduler) runCollector(ctx context.Context, c collector.Collector, triggerType string) {
+	// Incremental collectors only fetch what changed since the last
+	// completed run; read it before the lock adds this run's row
+	since := s.lastCompletedStart(ctx, c.Name())
+
 	// Try to acquire lock (non-blocking)
-	syncID, acquired, err := s.lock.TryAcquire(ctx, c.Name(), string(c.Type()), triggerType)
+	syncID, acquired, err := s.lock.TryAcquire(ctx, c.Name(), string(c.Type()), triggerType, s.registry.Labels(c.Name()))
 	if err != nil {
-		log.Printf("[Scheduler] Failed to acquire lock for %s: %v", c.Name(), err)
+		slog.Error("Acquiring sync lock failed", "collector", c.Name(), "error", err)
 		return
 	}
 
 	if !acquired {
-		log.Printf("[Scheduler] Skipping %s - already running", c.Name())
+		slog.Info("Skipping sync, already running", "collector", c.Name())
 		return
 	}
 
-	log.Printf("[Scheduler] Starting %s sync (trigger: %s)", c.Name(), triggerType)
+	slog.Info("Starting sync", "collector", c.Name(), "source", c.Source(), "trigger", triggerType)
+	started := time.Now()
 
-	// Run the sync
-	stats, syncErr := s.syncSvc.RunCollector(ctx, c)
+	stats, syncErr := s.runSync(ctx, c.Name(), func(runCtx context.Context) (*service.SyncStats, error) {
+		return s.syncSvc.RunCollectorIncremental(runCtx, c, since)
+	})
 
 	// Prepare release stats
 	releaseStats := SyncReleaseStats{}
@@ -150,23 +279,102 @@ func (s *Scheduler) runCollector(ctx context.Context, c collector.Collector, tri
 		releaseStats.Removed = stats.Removed
 	}
 
-	// Release lock with results
-	if err := s.lock.Release(ctx, c.Name(), syncID, releaseStats, syncErr); err != nil {
-		log.Printf("[Scheduler] Failed to release lock for %s: %v", c.Name(), err)
+	// Release lock with results (even if the run's context was cancelled)
+	if err := s.lock.Release(context.WithoutCancel(ctx), c.Name(), syncID, releaseStats, syncErr); err != nil {
+		slog.Error("Releasing sync lock failed", "collector", c.Name(), "error", err)
 	}
 
 	if syncErr != nil {
-		log.Printf("[Scheduler] Sync %s failed: %v", c.Name(), syncErr)
+		slog.Error("Sync failed", "collector", c.Name(), "source", c.Source(),
+			"duration", time.Since(started), "error", syncErr)
+		return
+	}
+
+	slog.Info("Sync completed", "collector", c.Name(), "source", c.Source(),
+		"found", releaseStats.Found, "added", releaseStats.Added, "updated", releaseStats.Updated,
+		"removed", releaseStats.Removed, "duration", time.Since(started))
+
+	// Export the collector's part of the JSON files after successful sync
+	if err := s.exportSvc.ExportForSource(ctx, c.Source()); err != nil {
+		slog.Error("Export after sync failed", "collector", c.Name(), "source", c.Source(), "error", err)
+	}
+}
+
+// runSync runs a collector's sync with a context that is cancellable
+// through CancelSync and bounded by MaxRunDuration, so a hung provider API
+// can't keep it running. Errors of runs stopped either way are wrapped
+// with ErrSyncCancelled or ErrSyncTimedOut.
+func (s *Scheduler) runSync(ctx context.Context, collectorName string, run func(context.Context) (*service.SyncStats, error)) (*service.SyncStats, error) {
+	runCtx, cancelRun := context.WithCancelCause(ctx)
+	defer cancelRun(nil)
+	s.setRunning(collectorName, cancelRun)
+	defer s.setRunning(collectorName, nil)
+
+	// The deadline derives from the cancellable context, so CancelSync
+	// still reaches the run
+	if s.cfg.MaxRunDuration > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(runCtx, s.cfg.MaxRunDuration)
+		defer cancel()
+	}
+
+	stats, err := run(runCtx)
+	if err == nil {
+		return stats, nil
+	}
+	switch {
+	case errors.Is(context.Cause(runCtx), ErrSyncCancelled):
+		err = fmt.Errorf("%w: %v", ErrSyncCancelled, err)
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil:
+		err = fmt.Errorf("%w after %s: %v", ErrSyncTimedOut, s.cfg.MaxRunDuration, err)
+	}
+	return stats, err
+}
+
+// setRunning records (or with nil, clears) the cancel function of a
+// collector's run in progress
+func (s *Scheduler) setRunning(collectorName string, cancel context.CancelCauseFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cancel == nil {
+		delete(s.running, collectorName)
 		return
 	}
+	s.running[collectorName] = cancel
+}
+
+// CancelSync cancels a collector's run in progress on this instance
+// The run stops at the collector's next context check; what it collected
+// so far is merged as a partial result (within SYNC_PARTIAL_MERGE_GRACE)
+// and the run is recorded as "cancelled". Returns ErrNotRunning if there
+// is nothing to cancel.
+func (s *Scheduler) CancelSync(collectorName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cancel, ok := s.running[collectorName]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotRunning, collectorName)
+	}
 
-	log.Printf("[Scheduler] Sync %s completed: found=%d added=%d updated=%d removed=%d",
-		c.Name(), releaseStats.Found, releaseStats.Added, releaseStats.Updated, releaseStats.Removed)
+	cancel(ErrSyncCancelled)
+	slog.Info("Cancelling sync", "collector", collectorName)
+	return nil
+}
 
-	// Export JSON files after successful sync
-	if err := s.exportSvc.ExportAll(ctx); err != nil {
-		log.Printf("[Scheduler] Export failed after %s sync: %v", c.Name(), err)
+// lastCompletedStart returns when the collector's latest run started if
+// that run completed, zero otherwise (no run yet, failed or still running)
+func (s *Scheduler) lastCompletedStart(ctx context.Context, collectorName string) time.Time {
+	status, err := s.lock.GetCollectorStatus(ctx, collectorName)
+	if err != nil {
+		slog.Warn("Reading last sync status failed, running full sync", "collector", collectorName, "error", err)
+		return time.Time{}
 	}
+	if status == nil || status.Status != "completed" {
+		return time.Time{}
+	}
+	return status.StartedAt
 }
 
 // TriggerSync manually triggers a collector sync (on-demand)
@@ -175,7 +383,7 @@ func (s *Scheduler) runCollector(ctx context.Context, c collector.Collector, tri
 func (s *Scheduler) TriggerSync(ctx context.Context, collectorName string) error {
 	c, ok := s.registry.Get(collectorName)
 	if !ok {
-		return fmt.Errorf("collector not found: %s", collectorName)
+		return fmt.Errorf("%w: %s", ErrCollectorNotFound, collectorName)
 	}
 
 	// Run in background goroutine
@@ -184,6 +392,19 @@ func (s *Scheduler) TriggerSync(ctx context.Context, collectorName string) error
 	return nil
 }
 
+// DryRun runs a collector and returns what a real sync would change,
+// without writing anything (see SyncService.RunCollectorDryRun)
+// It runs synchronously and doesn't take the sync lock or record a sync
+// status, so it neither blocks nor counts as a real run.
+func (s *Scheduler) DryRun(ctx context.Context, collectorName string) (*service.SyncStats, error) {
+	c, ok := s.registry.Get(collectorName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCollectorNotFound, collectorName)
+	}
+
+	return s.syncSvc.RunCollectorDryRun(ctx, c)
+}
+
 // TriggerSyncAll triggers all collectors
 func (s *Scheduler) TriggerSyncAll(ctx context.Context) error {
 	collectors := s.registry.All()
@@ -198,6 +419,59 @@ func (s *Scheduler) TriggerSyncAll(ctx context.Context) error {
 	return nil
 }
 
+// Pause stops all scheduled runs until Resume is called
+// Job definitions are kept and manual triggers still work. Runs already in
+// progress are not interrupted. The state is persisted across restarts.
+func (s *Scheduler) Pause(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := savePaused(ctx, s.lock.db, true); err != nil {
+		return fmt.Errorf("persist paused state: %w", err)
+	}
+
+	if !s.paused {
+		s.cron.Stop()
+		s.paused = true
+		slog.Info("Scheduler paused")
+	}
+	return nil
+}
+
+// Resume restarts scheduled runs after Pause
+func (s *Scheduler) Resume(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := savePaused(ctx, s.lock.db, false); err != nil {
+		return fmt.Errorf("persist paused state: %w", err)
+	}
+
+	if s.paused {
+		s.cron.Start()
+		s.paused = false
+		slog.Info("Scheduler resumed")
+	}
+	return nil
+}
+
+// ValidateCollectors returns the Validate result of every registered
+// collector, keyed by name (nil when valid)
+func (s *Scheduler) ValidateCollectors() map[string]error {
+	results := make(map[string]error)
+	for _, c := range s.registry.All() {
+		results[c.Name()] = c.Validate()
+	}
+	return results
+}
+
+// IsPaused reports whether scheduled runs are paused
+func (s *Scheduler) IsPaused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
 // GetNextRun returns the next scheduled run time for a collector
 func (s *Scheduler) GetNextRun(collectorName string) *time.Time {
 	s.mu.Lock()
@@ -246,7 +520,12 @@ func (s *Scheduler) GetCollectorStatus(ctx context.Context, collectorName string
 	return s.lock.GetCollectorStatus(ctx, collectorName)
 }
 
-// GetAllStatus returns the status of all collectors
-func (s *Scheduler) GetAllStatus(ctx context.Context) ([]CollectorStatusInfo, error) {
-	return s.lock.GetStatus(ctx)
+// GetAllStatus returns the status of all collectors, optionally filtered by labels
+func (s *Scheduler) GetAllStatus(ctx context.Context, labels map[string]string) ([]CollectorStatusInfo, error) {
+	return s.lock.GetStatus(ctx, labels)
+}
+
+// GetStatsHistory returns per-day sync statistics for dashboards
+func (s *Scheduler) GetStatsHistory(ctx context.Context, collectorName string, days int, labels map[string]string) ([]DailySyncStats, error) {
+	return s.lock.GetStatsHistory(ctx, collectorName, days, labels)
 }
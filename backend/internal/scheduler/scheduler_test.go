@@ -0,0 +1,136 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"0xdomainsnapshot/internal/collector"
+	"0xdomainsnapshot/internal/config"
+	"0xdomainsnapshot/internal/service"
+)
+
+// slowCollector blocks in Collect until its context is done
+type slowCollector struct {
+	started chan struct{}
+}
+
+func (c *slowCollector) Name() string                  { return "slow_dns" }
+func (c *slowCollector) Type() collector.CollectorType { return collector.CollectorTypeDNSRecords }
+func (c *slowCollector) Source() string                { return "Slow" }
+func (c *slowCollector) Validate() error               { return nil }
+
+func (c *slowCollector) Collect(ctx context.Context) (*collector.CollectorResult, error) {
+	close(c.started)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(10 * time.Second):
+		return &collector.CollectorResult{}, nil
+	}
+}
+
+func newTestScheduler(maxRunDuration time.Duration) *Scheduler {
+	return &Scheduler{
+		cfg:     config.SchedulerConfig{MaxRunDuration: maxRunDuration},
+		running: make(map[string]context.CancelCauseFunc),
+	}
+}
+
+// runSlow runs c through runSync in the background
+func runSlow(s *Scheduler, c *slowCollector) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.runSync(context.Background(), c.Name(), func(ctx context.Context) (*service.SyncStats, error) {
+			_, err := c.Collect(ctx)
+			return nil, err
+		})
+		done <- err
+	}()
+	return done
+}
+
+func waitRun(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not stop")
+		return nil
+	}
+}
+
+func TestCancelSyncStopsRun(t *testing.T) {
+	// With and without the default MaxRunDuration deadline
+	for _, maxRun := range []time.Duration{time.Hour, 0} {
+		s := newTestScheduler(maxRun)
+		c := &slowCollector{started: make(chan struct{})}
+
+		done := runSlow(s, c)
+		<-c.started
+		if err := s.CancelSync(c.Name()); err != nil {
+			t.Fatalf("max run %v: CancelSync: %v", maxRun, err)
+		}
+
+		err := waitRun(t, done)
+		if !errors.Is(err, ErrSyncCancelled) {
+			t.Errorf("max run %v: error = %v, want ErrSyncCancelled", maxRun, err)
+		}
+		if got := releaseStatus(err); got != "cancelled" {
+			t.Errorf("max run %v: status = %q, want cancelled", maxRun, got)
+		}
+		if err := s.CancelSync(c.Name()); !errors.Is(err, ErrNotRunning) {
+			t.Errorf("max run %v: CancelSync after run = %v, want ErrNotRunning", maxRun, err)
+		}
+	}
+}
+
+func TestRunSyncTimesOut(t *testing.T) {
+	s := newTestScheduler(50 * time.Millisecond)
+	c := &slowCollector{started: make(chan struct{})}
+
+	err := waitRun(t, runSlow(s, c))
+	if !errors.Is(err, ErrSyncTimedOut) {
+		t.Fatalf("error = %v, want ErrSyncTimedOut", err)
+	}
+	if errors.Is(err, ErrSyncCancelled) {
+		t.Errorf("timed out run reported as cancelled: %v", err)
+	}
+	if got := releaseStatus(err); got != "failed" {
+		t.Errorf("status = %q, want failed", got)
+	}
+	if err := s.CancelSync(c.Name()); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("CancelSync after timeout = %v, want ErrNotRunning", err)
+	}
+}
+
+func TestWaitJitterStopsOnShutdown(t *testing.T) {
+	s := &Scheduler{cfg: config.SchedulerConfig{Jitter: time.Hour}, stop: make(chan struct{})}
+
+	done := make(chan bool, 1)
+	go func() { done <- s.waitJitter("slow_dns") }()
+	close(s.stop)
+
+	select {
+	case run := <-done:
+		if run {
+			t.Error("waitJitter = true after shutdown, want false")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waitJitter did not return on shutdown")
+	}
+}
+
+func TestWaitJitterSkipsWhenPaused(t *testing.T) {
+	s := &Scheduler{stop: make(chan struct{})}
+	if !s.waitJitter("slow_dns") {
+		t.Error("waitJitter = false, want true")
+	}
+
+	s.paused = true
+	if s.waitJitter("slow_dns") {
+		t.Error("waitJitter = true while paused, want false")
+	}
+}
@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"0xdomainsnapshot/internal/database"
+)
+
+// pausedKey is the scheduler_state key holding the global paused flag
+const pausedKey = "paused"
+
+// loadPaused reads the persisted paused flag (false if never set)
+func loadPaused(ctx context.Context, db *database.DB) (bool, error) {
+	var value string
+	err := db.QueryRowContext(ctx, `
+		SELECT value FROM scheduler_state WHERE key = $1
+	`, pausedKey).Scan(&value)
+
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return strconv.ParseBool(value)
+}
+
+// savePaused persists the paused flag
+func savePaused(ctx context.Context, db *database.DB, paused bool) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO scheduler_state (key, value, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (key)
+		DO UPDATE SET value = EXCLUDED.value, updated_at = NOW()
+	`, pausedKey, strconv.FormatBool(paused))
+	return err
+}
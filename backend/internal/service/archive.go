@@ -0,0 +1,181 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ErrSnapshotNotFound is returned for an unknown export snapshot ID
+var ErrSnapshotNotFound = errors.New("export snapshot not found")
+
+// snapshotIDFormat names archived exports (UTC, sorts chronologically)
+const snapshotIDFormat = "20060102T150405Z"
+
+// ExportSnapshot describes an archived export
+type ExportSnapshot struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     []string  `json:"files"`
+}
+
+// archive copies the published files into a new snapshot directory and
+// prunes the oldest snapshots beyond archiveKeep
+func (e *ExportService) archive(names []string) error {
+	id := time.Now().UTC().Format(snapshotIDFormat)
+	dir := filepath.Join(e.archiveDir, id)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create snapshot directory: %w", err)
+	}
+	for _, name := range names {
+		if err := copyPath(filepath.Join(e.outputDir, name), filepath.Join(dir, name)); err != nil {
+			os.RemoveAll(dir)
+			return fmt.Errorf("archive %s: %w", name, err)
+		}
+	}
+	log.Printf("[Export] Archived export as snapshot %s", id)
+
+	if e.archiveKeep > 0 {
+		snapshots, err := e.ListSnapshots()
+		if err != nil {
+			return fmt.Errorf("list snapshots: %w", err)
+		}
+		for i := e.archiveKeep; i < len(snapshots); i++ {
+			if err := os.RemoveAll(filepath.Join(e.archiveDir, snapshots[i].ID)); err != nil {
+				return fmt.Errorf("prune snapshot %s: %w", snapshots[i].ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ListSnapshots returns the archived exports, newest first
+func (e *ExportService) ListSnapshots() ([]ExportSnapshot, error) {
+	if e.archiveDir == "" {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(e.archiveDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []ExportSnapshot
+	for _, entry := range entries {
+		created, err := time.Parse(snapshotIDFormat, entry.Name())
+		if !entry.IsDir() || err != nil {
+			continue
+		}
+
+		files, err := os.ReadDir(filepath.Join(e.archiveDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		snapshot := ExportSnapshot{ID: entry.Name(), CreatedAt: created, Files: []string{}}
+		for _, f := range files {
+			snapshot.Files = append(snapshot.Files, f.Name())
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].ID > snapshots[j].ID
+	})
+	return snapshots, nil
+}
+
+// ExportFromSnapshot republishes an archived export in place of the current
+// files, using the same staging and publish steps as ExportAll
+func (e *ExportService) ExportFromSnapshot(id string) (*ExportSnapshot, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, err := time.Parse(snapshotIDFormat, id); err != nil || e.archiveDir == "" {
+		return nil, ErrSnapshotNotFound
+	}
+
+	src := filepath.Join(e.archiveDir, id)
+	files, err := os.ReadDir(src)
+	if os.IsNotExist(err) {
+		return nil, ErrSnapshotNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot: %w", err)
+	}
+
+	staging, err := os.MkdirTemp(e.outputDir, ".export-")
+	if err != nil {
+		return nil, fmt.Errorf("create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+
+	for _, name := range names {
+		if err := copyPath(filepath.Join(src, name), filepath.Join(staging, name)); err != nil {
+			return nil, fmt.Errorf("stage %s: %w", name, err)
+		}
+	}
+	if err := e.publish(staging, names); err != nil {
+		return nil, fmt.Errorf("publish snapshot: %w", err)
+	}
+
+	log.Printf("[Export] Republished snapshot %s", id)
+
+	created, _ := time.Parse(snapshotIDFormat, id)
+	return &ExportSnapshot{ID: id, CreatedAt: created, Files: names}, nil
+}
+
+// copyPath copies a file, or a directory of files (zones/), to dst
+func copyPath(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		if err := os.MkdirAll(dst, 0755); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := copyPath(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"sort"
+	"strings"
+)
+
+// consolidateRecords collapses identical DNS records seen from several sources
+// Records are identical when domain, subdomain, type and data match. The merged
+// record lists every provider in "sources" (and joined in "source" for the
+// frontend), is active if any copy is active, and keeps the earliest discovery
+// and latest last_seen dates. "fingerprint" is replaced by "fingerprints", one
+// per source. Input order is preserved.
+func consolidateRecords(records []map[string]interface{}) []map[string]interface{} {
+	var results []map[string]interface{}
+	index := make(map[string]int)
+
+	for _, r := range records {
+		source, _ := r["source"].(string)
+		key := strings.Join([]string{str(r["domain"]), str(r["subdomain"]), str(r["type"]), str(r["data"])}, "\x00")
+
+		i, seen := index[key]
+		if !seen {
+			merged := make(map[string]interface{}, len(r)+1)
+			for k, v := range r {
+				merged[k] = v
+			}
+			merged["sources"] = []string{source}
+			if raw, ok := r["raw_data"]; ok {
+				merged["raw_data"] = map[string]interface{}{source: raw}
+			}
+			index[key] = len(results)
+			results = append(results, merged)
+			continue
+		}
+
+		merged := results[i]
+		merged["sources"] = append(merged["sources"].([]string), source)
+		if r["status"] == "active" {
+			merged["status"] = "active"
+		}
+		if p, _ := r["proxied"].(bool); p {
+			merged["proxied"] = true
+		}
+		if d := str(r["discovery_date"]); d != "" && (str(merged["discovery_date"]) == "" || d < str(merged["discovery_date"])) {
+			merged["discovery_date"] = d
+		}
+		if d := str(r["last_seen"]); d > str(merged["last_seen"]) {
+			merged["last_seen"] = d
+		}
+		if d := str(r["last_present_at"]); d > str(merged["last_present_at"]) {
+			merged["last_present_at"] = d
+		}
+		if raw, ok := r["raw_data"]; ok {
+			if bySource, ok := merged["raw_data"].(map[string]interface{}); ok {
+				bySource[source] = raw
+			} else {
+				merged["raw_data"] = map[string]interface{}{source: raw}
+			}
+		}
+	}
+
+	for _, merged := range results {
+		sources := merged["sources"].([]string)
+		sort.Strings(sources)
+		merged["source"] = strings.Join(sources, ", ")
+
+		// One fingerprint per source copy, in the same order as sources
+		fingerprints := make([]string, len(sources))
+		for i, source := range sources {
+			fingerprints[i] = recordFingerprint(str(merged["domain"]), str(merged["subdomain"]), str(merged["type"]), source)
+		}
+		delete(merged, "fingerprint")
+		merged["fingerprints"] = fingerprints
+	}
+
+	return results
+}
+
+// str returns a map value as a string, "" for missing or non-string values
+func str(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
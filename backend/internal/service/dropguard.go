@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"0xdomainsnapshot/internal/collector"
+)
+
+// ErrRecordDrop is returned when a run found far less than its source has
+// active and its removals were skipped pending confirmation
+var ErrRecordDrop = errors.New("record count dropped")
+
+type dropConfirmedKey struct{}
+
+// WithDropConfirmed marks a run as confirmed by an operator, so its removals
+// are applied even when the record count dropped beyond SYNC_MAX_DROP_PERCENT
+func WithDropConfirmed(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dropConfirmedKey{}, true)
+}
+
+// dropConfirmed reports whether the run was confirmed with WithDropConfirmed
+func dropConfirmed(ctx context.Context) bool {
+	confirmed, _ := ctx.Value(dropConfirmedKey{}).(bool)
+	return confirmed
+}
+
+// checkDrop compares what a run found with what the removal sweep would
+// act on: the source's active domains (of the run's account), and its
+// active records in the domains the run returned records for (the merger
+// only sweeps those).
+// These are what the previous successful runs left active, which also
+// works for incremental runs whose sync_status counts only cover changes.
+// Returns a description of the drop, "" when within the limit.
+func (s *SyncService) checkDrop(ctx context.Context, source string, result *collector.CollectorResult) (string, error) {
+	if len(result.Domains) > 0 {
+		var active int
+		err := s.db.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM domains
+			WHERE registrar = $1 AND status = 'active' AND COALESCE(attributes->>'account', '') = $2
+		`, source, result.Account).Scan(&active)
+		if err != nil {
+			return "", fmt.Errorf("count active domains: %w", err)
+		}
+		if s.dropped(len(result.Domains), active) {
+			return fmt.Sprintf("found %d domains, %d active", len(result.Domains), active), nil
+		}
+	}
+
+	if len(result.DNSRecords) > 0 {
+		seen := make(map[string]bool)
+		var domains []string
+		for _, r := range result.DNSRecords {
+			if !seen[r.Domain] {
+				seen[r.Domain] = true
+				domains = append(domains, r.Domain)
+			}
+		}
+
+		var active int
+		err := s.db.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM dns_records WHERE source = $1 AND status = 'active' AND domain = ANY($2)
+		`, source, pq.Array(domains)).Scan(&active)
+		if err != nil {
+			return "", fmt.Errorf("count active DNS records: %w", err)
+		}
+		if s.dropped(len(result.DNSRecords), active) {
+			return fmt.Sprintf("found %d DNS records, %d active", len(result.DNSRecords), active), nil
+		}
+	}
+
+	return "", nil
+}
+
+// dropped reports whether found is more than maxDropPercent below active
+func (s *SyncService) dropped(found, active int) bool {
+	return active > 0 && (active-found)*100 > active*s.maxDropPercent
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// etagCache holds content hashes of exported files, keyed by path
+// Each entry records the file's size and modification time, so a file
+// replaced by anything else (a snapshot restore, a manual copy) is hashed
+// again on the next lookup.
+type etagCache struct {
+	mu      sync.Mutex
+	entries map[string]etagEntry
+}
+
+type etagEntry struct {
+	size    int64
+	modTime time.Time
+	etag    string
+}
+
+func newETagCache() *etagCache {
+	return &etagCache{entries: make(map[string]etagEntry)}
+}
+
+// get returns the file's ETag, hashing it if the cached entry is missing or
+// out of date. Directories have no ETag ("").
+func (c *etagCache) get(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", nil
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && entry.size == info.Size() && entry.modTime.Equal(info.ModTime()) {
+		return entry.etag, nil
+	}
+
+	etag, err := hashFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.entries[path] = etagEntry{size: info.Size(), modTime: info.ModTime(), etag: etag}
+	c.mu.Unlock()
+
+	return etag, nil
+}
+
+// hashFile returns a strong ETag (quoted SHA-256 prefix) of the file's contents
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil))[:32] + `"`, nil
+}
+
+// ETag returns the ETag of an exported file for conditional requests
+// Hashes are computed when files are published and cached until the file
+// changes.
+func (e *ExportService) ETag(path string) (string, error) {
+	return e.etags.get(path)
+}
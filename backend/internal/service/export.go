@@ -2,11 +2,15 @@ package service
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"sync"
 	"time"
 
 	"0xdomainsnapshot/internal/config"
@@ -14,20 +18,54 @@ import (
 
 // ExportService handles exporting data to JSON files
 type ExportService struct {
-	syncSvc   *SyncService
-	outputDir string
+	syncSvc     *SyncService
+	outputDir   string
+	includeRaw  bool
+	consolidate bool
+	zoneFile    bool
+	archiveDir  string
+	archiveKeep int
+
+	maxRecordsPerFile int
+	maxAge            time.Duration
+
+	etags *etagCache // Content hashes of published files
+
+	// mu serializes the exports: each reads the published set and
+	// republishes it, so two at once would drop each other's changes
+	mu sync.Mutex
 }
 
 // NewExportService creates a new ExportService
 func NewExportService(syncSvc *SyncService, cfg config.ExportConfig) *ExportService {
 	return &ExportService{
-		syncSvc:   syncSvc,
-		outputDir: cfg.OutputDir,
+		syncSvc:     syncSvc,
+		outputDir:   cfg.OutputDir,
+		includeRaw:  cfg.IncludeRaw,
+		consolidate: cfg.ConsolidateSources,
+		zoneFile:    cfg.ZoneFile,
+		archiveDir:  cfg.ArchiveDir,
+		archiveKeep: cfg.ArchiveKeep,
+
+		maxRecordsPerFile: cfg.MaxRecordsPerFile,
+		maxAge:            cfg.MaxAge,
+
+		etags: newETagCache(),
 	}
 }
 
 // ExportAll exports all data to JSON files for the frontend
+// All files are first written to a staging directory inside the output
+// directory and only published (see publish) once every file was written,
+// so a failed export leaves the previously published set untouched.
 func (e *ExportService) ExportAll(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.exportAll(ctx)
+}
+
+// exportAll runs ExportAll with e.mu held
+func (e *ExportService) exportAll(ctx context.Context) error {
 	log.Printf("[Export] Starting export to %s", e.outputDir)
 
 	// Ensure output directory exists
@@ -35,52 +73,256 @@ func (e *ExportService) ExportAll(ctx context.Context) error {
 		return fmt.Errorf("create output directory: %w", err)
 	}
 
+	staging, err := os.MkdirTemp(e.outputDir, ".export-")
+	if err != nil {
+		return fmt.Errorf("create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
 	// Export domains.json
 	log.Printf("[Export] Exporting domains.json")
-	domains, err := e.syncSvc.GetDomains(ctx, "", "")
+	domains, err := e.syncSvc.GetDomains(ctx, DomainQuery{IncludeRaw: e.includeRaw, Primary: true})
 	if err != nil {
 		return fmt.Errorf("get domains: %w", err)
 	}
-	if err := e.writeJSON("domains.json", domains); err != nil {
+	sortEntries(domains, domainSortKeys)
+	domainsFiles, err := e.writeJSONList(staging, "domains.json", domains)
+	if err != nil {
 		return fmt.Errorf("write domains.json: %w", err)
 	}
 	log.Printf("[Export] Exported %d domains", len(domains))
 
 	// Export subdomains.json (all DNS records)
 	log.Printf("[Export] Exporting subdomains.json")
-	records, err := e.syncSvc.GetDNSRecords(ctx, "", "", "")
+	records, err := e.syncSvc.GetDNSRecords(ctx, DNSRecordQuery{
+		IncludeRaw:  e.includeRaw,
+		Consolidate: e.consolidate,
+		Primary:     true,
+	})
 	if err != nil {
 		return fmt.Errorf("get DNS records: %w", err)
 	}
-	if err := e.writeJSON("subdomains.json", records); err != nil {
+	sortEntries(records, recordSortKeys)
+	recordsFiles, err := e.writeJSONList(staging, "subdomains.json", records)
+	if err != nil {
 		return fmt.Errorf("write subdomains.json: %w", err)
 	}
 	log.Printf("[Export] Exported %d DNS records", len(records))
 
 	// Export removed.json
 	log.Printf("[Export] Exporting removed.json")
-	removed, err := e.getRemovedAssets(ctx)
+	removed, err := e.getRemovedAssets(ctx, "")
 	if err != nil {
 		return fmt.Errorf("get removed assets: %w", err)
 	}
-	if err := e.writeJSON("removed.json", removed); err != nil {
+	removedFiles, err := e.writeJSONList(staging, "removed.json", removed)
+	if err != nil {
 		return fmt.Errorf("write removed.json: %w", err)
 	}
 	log.Printf("[Export] Exported %d removed assets", len(removed))
 
+	// Export zones/<domain>.zone
+	if e.zoneFile {
+		log.Printf("[Export] Exporting zone files")
+		zones, err := e.exportZoneFiles(ctx, filepath.Join(staging, "zones"))
+		if err != nil {
+			return fmt.Errorf("export zone files: %w", err)
+		}
+		log.Printf("[Export] Exported %d zone files", zones)
+	}
+
 	// Update metadata.json
 	log.Printf("[Export] Updating metadata.json")
-	if err := e.updateMetadata(ctx, len(domains), len(records)); err != nil {
+	sources, err := e.syncSvc.GetSources(ctx)
+	if err != nil {
+		return fmt.Errorf("get sources: %w", err)
+	}
+	if err := e.updateMetadata(ctx, staging, sources); err != nil {
 		return fmt.Errorf("update metadata: %w", err)
 	}
 
+	// Publish the staged set as a whole
+	files := append(append(domainsFiles, recordsFiles...), removedFiles...)
+	if e.zoneFile {
+		files = append(files, "zones")
+	}
+	files = append(files, "metadata.json")
+
+	if err := e.publish(staging, files); err != nil {
+		return fmt.Errorf("publish export: %w", err)
+	}
+
+	// Keep a copy for ExportFromSnapshot (non-fatal, the export is published)
+	if e.archiveDir != "" {
+		if err := e.archive(files); err != nil {
+			log.Printf("[Export] Warning: archiving export failed: %v", err)
+		}
+	}
+
 	log.Printf("[Export] Export complete")
 	return nil
 }
 
-// writeJSON writes data to a JSON file with pretty formatting
-func (e *ExportService) writeJSON(filename string, data interface{}) error {
-	path := filepath.Join(e.outputDir, filename)
+// ExportForSource re-exports only what one source contributes
+// The source's entries in domains.json, subdomains.json and removed.json
+// are replaced with fresh ones and the other sources' entries are kept as
+// published. Zone files combine all sources and are rewritten in full.
+// Falls back to ExportAll when records are consolidated (an entry can
+// belong to several sources) or a file hasn't been published yet.
+func (e *ExportService) ExportForSource(ctx context.Context, source string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.consolidate {
+		return e.exportAll(ctx)
+	}
+
+	var published [3][]map[string]interface{}
+	for i, name := range listFiles {
+		entries, err := e.readJSONList(name)
+		if err != nil {
+			log.Printf("[Export] Cannot reuse %s (%v), running full export", name, err)
+			return e.exportAll(ctx)
+		}
+		published[i] = entries
+	}
+
+	log.Printf("[Export] Starting %s export to %s", source, e.outputDir)
+
+	staging, err := os.MkdirTemp(e.outputDir, ".export-")
+	if err != nil {
+		return fmt.Errorf("create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	// domains.json
+	domains, err := e.syncSvc.GetDomains(ctx, DomainQuery{Source: source, IncludeRaw: e.includeRaw, Primary: true})
+	if err != nil {
+		return fmt.Errorf("get domains: %w", err)
+	}
+	domains = replaceSource(published[0], domains, "registrar", source, domainSortKeys)
+	domainsFiles, err := e.writeJSONList(staging, "domains.json", domains)
+	if err != nil {
+		return fmt.Errorf("write domains.json: %w", err)
+	}
+
+	// subdomains.json
+	records, err := e.syncSvc.GetDNSRecords(ctx, DNSRecordQuery{Source: source, IncludeRaw: e.includeRaw, Primary: true})
+	if err != nil {
+		return fmt.Errorf("get DNS records: %w", err)
+	}
+	records = replaceSource(published[1], records, "source", source, recordSortKeys)
+	recordsFiles, err := e.writeJSONList(staging, "subdomains.json", records)
+	if err != nil {
+		return fmt.Errorf("write subdomains.json: %w", err)
+	}
+
+	// removed.json
+	removed, err := e.getRemovedAssets(ctx, source)
+	if err != nil {
+		return fmt.Errorf("get removed assets: %w", err)
+	}
+	removed = replaceSource(published[2], removed, "provider", source, removedSortKeys)
+	removedFiles, err := e.writeJSONList(staging, "removed.json", removed)
+	if err != nil {
+		return fmt.Errorf("write removed.json: %w", err)
+	}
+
+	if e.zoneFile {
+		if _, err := e.exportZoneFiles(ctx, filepath.Join(staging, "zones")); err != nil {
+			return fmt.Errorf("export zone files: %w", err)
+		}
+	}
+
+	if err := e.updateMetadata(ctx, staging, []string{source}); err != nil {
+		return fmt.Errorf("update metadata: %w", err)
+	}
+
+	files := append(append(domainsFiles, recordsFiles...), removedFiles...)
+	if e.zoneFile {
+		files = append(files, "zones")
+	}
+	files = append(files, "metadata.json")
+
+	if err := e.publish(staging, files); err != nil {
+		return fmt.Errorf("publish export: %w", err)
+	}
+
+	if e.archiveDir != "" {
+		if err := e.archive(files); err != nil {
+			log.Printf("[Export] Warning: archiving export failed: %v", err)
+		}
+	}
+
+	log.Printf("[Export] %s export complete: %d domains, %d DNS records, %d removed assets",
+		source, len(domains), len(records), len(removed))
+	return nil
+}
+
+// replaceSource swaps a source's entries in a published list for fresh ones
+// Entries are matched on sourceKey and the result is sorted by sortKeys,
+// the order the full export writes them in.
+func replaceSource(published, fresh []map[string]interface{}, sourceKey, source string, sortKeys []string) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(published)+len(fresh))
+	for _, entry := range published {
+		if str(entry[sourceKey]) != source {
+			result = append(result, entry)
+		}
+	}
+	result = append(result, fresh...)
+
+	sortEntries(result, sortKeys)
+	return result
+}
+
+// Sort orders of the published lists, the same as the ORDER BY of the
+// queries they come from. Lists are sorted by them again before writing:
+// the database orders by its collation, which may not agree with the byte
+// order ExportForSource uses when merging a source into the published lists.
+var (
+	domainSortKeys  = []string{"domain", "registrar"}
+	recordSortKeys  = []string{"domain", "subdomain", "type", "data", "source"}
+	removedSortKeys = []string{"asset_type", "name", "provider", "details"}
+)
+
+// sortEntries sorts list entries by the string values of keys, in order
+func sortEntries(entries []map[string]interface{}, keys []string) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		for _, key := range keys {
+			a, b := str(entries[i][key]), str(entries[j][key])
+			if a != b {
+				return a < b
+			}
+		}
+		return false
+	})
+}
+
+// publishList writes a single list file into the output directory atomically
+func (e *ExportService) publishList(filename string, entries []map[string]interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := os.MkdirAll(e.outputDir, 0755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	staging, err := os.MkdirTemp(e.outputDir, ".export-")
+	if err != nil {
+		return fmt.Errorf("create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	files, err := e.writeJSONList(staging, filename, entries)
+	if err != nil {
+		return err
+	}
+	return e.publish(staging, files)
+}
+
+// writeJSON writes data to a JSON file in dir with pretty formatting
+func writeJSON(dir, filename string, data interface{}) error {
+	path := filepath.Join(dir, filename)
 
 	f, err := os.Create(path)
 	if err != nil {
@@ -96,59 +338,83 @@ func (e *ExportService) writeJSON(filename string, data interface{}) error {
 		data = []interface{}{}
 	}
 
-	return encoder.Encode(data)
+	if err := encoder.Encode(data); err != nil {
+		return err
+	}
+	return f.Close()
 }
 
-// getRemovedAssets gets all removed assets for the removed.json file
-func (e *ExportService) getRemovedAssets(ctx context.Context) ([]map[string]interface{}, error) {
+// getRemovedAssets gets the removed assets for the removed.json file
+// With source set, only that source's assets are returned.
+func (e *ExportService) getRemovedAssets(ctx context.Context, source string) ([]map[string]interface{}, error) {
 	var removed []map[string]interface{}
 
 	// Get removed domains
-	domains, err := e.syncSvc.GetDomains(ctx, "removed", "")
+	domains, err := e.syncSvc.GetDomains(ctx, DomainQuery{Status: "removed", Source: source, Primary: true})
 	if err != nil {
 		return nil, err
 	}
 
 	for _, d := range domains {
 		removed = append(removed, map[string]interface{}{
-			"asset_type":     "domain",
-			"name":           d["domain"],
-			"provider":       d["registrar"],
-			"details":        "Domain removed from registrar",
-			"discovery_date": d["discovery_date"],
-			"removed_date":   d["last_seen"],
-			"status":         "removed",
+			"asset_type":      "domain",
+			"name":            d["domain"],
+			"fingerprint":     d["fingerprint"],
+			"provider":        d["registrar"],
+			"details":         "Domain removed from registrar",
+			"discovery_date":  d["discovery_date"],
+			"removed_date":    d["last_seen"],
+			"last_present_at": d["last_present_at"],
+			"days_gone":       daysGone(d["last_present_at"]),
+			"status":          "removed",
 		})
 	}
 
 	// Get removed DNS records
-	records, err := e.syncSvc.GetDNSRecords(ctx, "removed", "", "")
+	records, err := e.syncSvc.GetDNSRecords(ctx, DNSRecordQuery{Status: "removed", Source: source, Primary: true})
 	if err != nil {
 		return nil, err
 	}
 
 	for _, r := range records {
-		name := r["domain"].(string)
-		if subdomain, ok := r["subdomain"].(string); ok && subdomain != "" {
-			name = subdomain + "." + name
-		}
-
 		removed = append(removed, map[string]interface{}{
-			"asset_type":     "subdomain",
-			"name":           name,
-			"provider":       r["source"],
-			"details":        fmt.Sprintf("%s record - %s", r["type"], r["data"]),
-			"discovery_date": r["discovery_date"],
-			"removed_date":   r["last_seen"],
-			"status":         "removed",
+			"asset_type":      "subdomain",
+			"name":            r["fqdn"],
+			"fingerprint":     r["fingerprint"],
+			"provider":        r["source"],
+			"details":         fmt.Sprintf("%s record - %s", r["type"], r["data"]),
+			"discovery_date":  r["discovery_date"],
+			"removed_date":    r["last_seen"],
+			"last_present_at": r["last_present_at"],
+			"days_gone":       daysGone(r["last_present_at"]),
+			"status":          "removed",
 		})
 	}
 
+	sortEntries(removed, removedSortKeys)
 	return removed, nil
 }
 
+// daysGone returns the whole days since a record was last returned by its provider
+// Returns nil when last_present_at is unknown.
+func daysGone(lastPresent interface{}) interface{} {
+	t, err := time.Parse(time.RFC3339, str(lastPresent))
+	if err != nil {
+		return nil
+	}
+	return int(time.Since(t).Hours() / 24)
+}
+
 // updateMetadata updates the metadata.json file
-func (e *ExportService) updateMetadata(ctx context.Context, domainCount, recordCount int) error {
+// "count" is the active count (what the dashboard shows); active, removed
+// and total follow the AssetCounts definitions. Only the given sources get
+// a new last_updated under "sources", the others keep their timestamp.
+func (e *ExportService) updateMetadata(ctx context.Context, dir string, sources []string) error {
+	domainCounts, recordCounts, err := e.syncSvc.assetCounts(ctx, true)
+	if err != nil {
+		return err
+	}
+
 	metadataPath := filepath.Join(e.outputDir, "metadata.json")
 
 	// Try to read existing metadata
@@ -169,6 +435,18 @@ func (e *ExportService) updateMetadata(ctx context.Context, domainCount, recordC
 
 	now := time.Now().UTC().Format(time.RFC3339)
 
+	// Per-source export timestamps
+	var sourceTimes map[string]interface{}
+	if dnsService, ok := services["dns"].(map[string]interface{}); ok {
+		sourceTimes, _ = dnsService["sources"].(map[string]interface{})
+	}
+	if sourceTimes == nil {
+		sourceTimes = make(map[string]interface{})
+	}
+	for _, source := range sources {
+		sourceTimes[source] = map[string]interface{}{"last_updated": now}
+	}
+
 	// Update DNS services
 	services["dns"] = map[string]interface{}{
 		"name":         "DNS",
@@ -178,35 +456,139 @@ func (e *ExportService) updateMetadata(ctx context.Context, domainCount, recordC
 		"services": map[string]interface{}{
 			"domains": map[string]interface{}{
 				"last_updated": now,
-				"count":        domainCount,
+				"count":        domainCounts.Active,
+				"active":       domainCounts.Active,
+				"removed":      domainCounts.Removed,
+				"total":        domainCounts.Total,
 			},
 			"subdomains": map[string]interface{}{
 				"last_updated": now,
-				"count":        recordCount,
+				"count":        recordCounts.Active,
+				"active":       recordCounts.Active,
+				"removed":      recordCounts.Removed,
+				"total":        recordCounts.Total,
 			},
 		},
+		"sources": sourceTimes,
 	}
 
 	metadata["services"] = services
 	metadata["last_updated"] = now
 
-	return e.writeJSON("metadata.json", metadata)
+	return writeJSON(dir, "metadata.json", metadata)
+}
+
+// ExportFreshness describes how current the published export is
+type ExportFreshness struct {
+	LastUpdated *time.Time `json:"last_updated"` // nil when no export was published
+	Age         string     `json:"age,omitempty"`
+	MaxAge      string     `json:"max_age"`
+	Stale       bool       `json:"stale"`
+}
+
+// Freshness compares metadata.json's last_updated with the configured max age
+// A missing or unreadable metadata.json counts as stale. Never stale when
+// the check is disabled (max age 0).
+func (e *ExportService) Freshness() ExportFreshness {
+	f := ExportFreshness{MaxAge: e.maxAge.String()}
+
+	var metadata struct {
+		LastUpdated time.Time `json:"last_updated"`
+	}
+	data, err := os.ReadFile(filepath.Join(e.outputDir, "metadata.json"))
+	if err == nil {
+		err = json.Unmarshal(data, &metadata)
+	}
+	if err != nil || metadata.LastUpdated.IsZero() {
+		f.Stale = e.maxAge > 0
+		return f
+	}
+
+	age := time.Since(metadata.LastUpdated)
+	f.LastUpdated = &metadata.LastUpdated
+	f.Age = age.Round(time.Second).String()
+	f.Stale = e.maxAge > 0 && age > e.maxAge
+	return f
 }
 
 // ExportDomains exports only domains to domains.json
 func (e *ExportService) ExportDomains(ctx context.Context) error {
-	domains, err := e.syncSvc.GetDomains(ctx, "", "")
+	domains, err := e.syncSvc.GetDomains(ctx, DomainQuery{IncludeRaw: e.includeRaw, Primary: true})
 	if err != nil {
 		return err
 	}
-	return e.writeJSON("domains.json", domains)
+	return e.publishList("domains.json", domains)
 }
 
 // ExportDNSRecords exports only DNS records to subdomains.json
 func (e *ExportService) ExportDNSRecords(ctx context.Context) error {
-	records, err := e.syncSvc.GetDNSRecords(ctx, "", "", "")
+	records, err := e.syncSvc.GetDNSRecords(ctx, DNSRecordQuery{IncludeRaw: e.includeRaw, Primary: true})
 	if err != nil {
 		return err
 	}
-	return e.writeJSON("subdomains.json", records)
+	return e.publishList("subdomains.json", records)
+}
+
+// SelectiveExport holds the data for a subset of domains
+type SelectiveExport struct {
+	Domains    []map[string]interface{} `json:"domains"`
+	DNSRecords []map[string]interface{} `json:"dns_records"`
+}
+
+// ExportSelective returns only the given domains and their DNS records
+func (e *ExportService) ExportSelective(ctx context.Context, domainNames []string) (*SelectiveExport, error) {
+	domains, err := e.syncSvc.GetDomains(ctx, DomainQuery{Domains: domainNames, IncludeRaw: e.includeRaw, Primary: true})
+	if err != nil {
+		return nil, fmt.Errorf("get domains: %w", err)
+	}
+
+	records, err := e.syncSvc.GetDNSRecords(ctx, DNSRecordQuery{Domains: domainNames, IncludeRaw: e.includeRaw, Primary: true})
+	if err != nil {
+		return nil, fmt.Errorf("get DNS records: %w", err)
+	}
+
+	if domains == nil {
+		domains = []map[string]interface{}{}
+	}
+	if records == nil {
+		records = []map[string]interface{}{}
+	}
+
+	return &SelectiveExport{Domains: domains, DNSRecords: records}, nil
+}
+
+// WriteCSV writes the export as a single CSV with one row per asset
+func (s *SelectiveExport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"asset_type", "domain", "subdomain", "type", "data", "proxied", "provider", "status", "discovery_date", "last_seen", "fingerprint", "fqdn"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	str := func(v interface{}) string {
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprintf("%v", v)
+	}
+
+	for _, d := range s.Domains {
+		row := []string{"domain", str(d["domain"]), "", "", "", "", str(d["registrar"]),
+			str(d["status"]), str(d["discovery_date"]), str(d["last_seen"]), str(d["fingerprint"]), str(d["domain"])}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range s.DNSRecords {
+		row := []string{"dns_record", str(r["domain"]), str(r["subdomain"]), str(r["type"]), str(r["data"]),
+			str(r["proxied"]), str(r["source"]), str(r["status"]), str(r["discovery_date"]), str(r["last_seen"]), str(r["fingerprint"]), str(r["fqdn"])}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
 }
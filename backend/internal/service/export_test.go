@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"0xdomainsnapshot/internal/config"
+	"0xdomainsnapshot/internal/database"
+)
+
+func rec(subdomain, recordType, data, source string) map[string]interface{} {
+	return map[string]interface{}{
+		"domain":    "example.com",
+		"subdomain": subdomain,
+		"type":      recordType,
+		"data":      data,
+		"source":    source,
+	}
+}
+
+func recordKeys(records []map[string]interface{}) []string {
+	keys := make([]string, len(records))
+	for i, r := range records {
+		keys[i] = fmt.Sprintf("%s %s %s %s", r["subdomain"], r["type"], r["data"], r["source"])
+	}
+	return keys
+}
+
+func TestReplaceSourceSortsLikeFullExport(t *testing.T) {
+	published := []map[string]interface{}{
+		rec("www", "A", "192.0.2.1", "aws"),
+		rec("www", "A", "192.0.2.9", "aws"),
+		rec("www", "A", "192.0.2.1", "cloudflare"),
+		rec("www", "AAAA", "2001:db8::1", "cloudflare"),
+	}
+	fresh := []map[string]interface{}{
+		rec("www", "TXT", "hello", "cloudflare"),
+		rec("www", "A", "192.0.2.5", "cloudflare"),
+		rec("www", "A", "192.0.2.1", "cloudflare"),
+	}
+
+	got := recordKeys(replaceSource(published, fresh, "source", "cloudflare", recordSortKeys))
+
+	// What ExportAll writes for the same records
+	full := []map[string]interface{}{published[0], published[1], fresh[0], fresh[1], fresh[2]}
+	sortEntries(full, recordSortKeys)
+	want := recordKeys(full)
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("replaceSource order = %q, want %q", got, want)
+	}
+	if want := []string{
+		"www A 192.0.2.1 aws",
+		"www A 192.0.2.1 cloudflare",
+		"www A 192.0.2.5 cloudflare",
+		"www A 192.0.2.9 aws",
+		"www TXT hello cloudflare",
+	}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("replaceSource order = %q, want %q", got, want)
+	}
+}
+
+func TestReplaceSourceKeepsOtherSources(t *testing.T) {
+	published := []map[string]interface{}{
+		{"domain": "b.com", "registrar": "godaddy"},
+		{"domain": "a.com", "registrar": "namecheap"},
+	}
+	fresh := []map[string]interface{}{{"domain": "c.com", "registrar": "namecheap"}}
+
+	got := replaceSource(published, fresh, "registrar", "namecheap", domainSortKeys)
+	var domains []string
+	for _, d := range got {
+		domains = append(domains, str(d["domain"]))
+	}
+	if want := []string{"b.com", "c.com"}; fmt.Sprint(domains) != fmt.Sprint(want) {
+		t.Errorf("domains = %q, want %q", domains, want)
+	}
+}
+
+// newTestSyncService connects to TEST_DATABASE_URL (tests are skipped
+// without it)
+func newTestSyncService(t *testing.T) (*SyncService, *database.DB) {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := database.New(config.DatabaseConfig{URL: url, MaxConnections: 5, MaxIdle: 1})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.RunMigrations(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return NewSyncService(db, config.SyncConfig{}), db
+}
+
+func TestConcurrentExportForSourceKeepsBothSources(t *testing.T) {
+	syncSvc, db := newTestSyncService(t)
+	e := NewExportService(syncSvc, config.ExportConfig{OutputDir: t.TempDir()})
+	ctx := context.Background()
+
+	run := time.Now().UnixNano()
+	sources := []string{fmt.Sprintf("test_a_%d", run), fmt.Sprintf("test_b_%d", run)}
+	t.Cleanup(func() {
+		for _, source := range sources {
+			db.Exec(`DELETE FROM domains WHERE registrar = $1`, source)
+		}
+	})
+
+	addDomain := func(name, source string) {
+		t.Helper()
+		if _, err := db.Exec(`INSERT INTO domains (domain, registrar) VALUES ($1, $2)`, name, source); err != nil {
+			t.Fatalf("insert %s: %v", name, err)
+		}
+	}
+
+	for _, source := range sources {
+		addDomain("old-"+source+".example", source)
+	}
+	if err := e.ExportAll(ctx); err != nil {
+		t.Fatalf("ExportAll: %v", err)
+	}
+
+	// Each source syncs a new domain and exports at the same time
+	for _, source := range sources {
+		addDomain("new-"+source+".example", source)
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, len(sources))
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source string) {
+			defer wg.Done()
+			errs[i] = e.ExportForSource(ctx, source)
+		}(i, source)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("ExportForSource(%s): %v", sources[i], err)
+		}
+	}
+
+	domains, err := e.readJSONList("domains.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	published := make(map[string]bool)
+	for _, d := range domains {
+		published[str(d["domain"])] = true
+	}
+	for _, source := range sources {
+		for _, name := range []string{"old-" + source + ".example", "new-" + source + ".example"} {
+			if !published[name] {
+				t.Errorf("domains.json is missing %s", name)
+			}
+		}
+	}
+}
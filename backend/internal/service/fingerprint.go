@@ -0,0 +1,28 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Fingerprints identify a logical asset across syncs for external systems
+// (SIEM correlation). They only depend on fields that don't change while the
+// asset exists, unlike row IDs or data, TTL and status.
+
+// domainFingerprint returns the fingerprint of a domain at a registrar
+func domainFingerprint(domain, registrar string) string {
+	return fingerprint("domain", domain, registrar)
+}
+
+// recordFingerprint returns the fingerprint of a DNS name/type at a source
+// All values of one record set (e.g. several A records) share it.
+func recordFingerprint(domain, subdomain, recordType, source string) string {
+	return fingerprint("dns_record", domain, subdomain, recordType, source)
+}
+
+// fingerprint hashes the lowercased parts (first 128 bits of SHA-256, hex)
+func fingerprint(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.Join(parts, "\x00"))))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"0xdomainsnapshot/internal/analysis"
+	"0xdomainsnapshot/internal/collector/dns"
+	"0xdomainsnapshot/internal/config"
+)
+
+// RecordIssue describes a problem found in a stored DNS record
+type RecordIssue struct {
+	Domain     string   `json:"domain"`
+	Subdomain  string   `json:"subdomain"`
+	RecordType string   `json:"type"`
+	Data       string   `json:"data"`
+	Source     string   `json:"source"`
+	Issues     []string `json:"issues"`
+}
+
+// GetTXTIssues checks all active TXT records for length and SPF problems
+func (s *SyncService) GetTXTIssues(ctx context.Context) ([]RecordIssue, error) {
+	rows, err := s.db.Reader().QueryContext(ctx, `
+		SELECT domain, subdomain, record_type, data, source, COALESCE(attributes->>'txt_strings', '')
+		FROM dns_records
+		WHERE status = 'active' AND record_type IN ('TXT', 'SPF')
+		ORDER BY domain, subdomain
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []RecordIssue
+	for rows.Next() {
+		var r RecordIssue
+		var txtStrings string
+
+		if err := rows.Scan(&r.Domain, &r.Subdomain, &r.RecordType, &r.Data, &r.Source, &txtStrings); err != nil {
+			return nil, err
+		}
+
+		if r.Issues = dns.CheckTXTData(r.Data, txtStrings); len(r.Issues) > 0 {
+			results = append(results, r)
+		}
+	}
+
+	return results, rows.Err()
+}
+
+// GetRecordIssues returns all active records with known problems
+// - TXT/SPF length and lookup issues (as in GetTXTIssues)
+// - Data not matching the record type, as tagged at ingest (data_valid=false)
+// - Apex CNAMEs the provider does not flatten (flattened ones are not reported)
+func (s *SyncService) GetRecordIssues(ctx context.Context) ([]RecordIssue, error) {
+	rows, err := s.db.Reader().QueryContext(ctx, `
+		SELECT domain, subdomain, record_type, data, source, attributes->>'data_error',
+		       COALESCE(attributes->>'txt_strings', ''),
+		       COALESCE(attributes->>'apex_cname' = 'true' AND attributes->>'flattened' IS NULL, FALSE)
+		FROM dns_records
+		WHERE status = 'active'
+		  AND (record_type IN ('TXT', 'SPF') OR attributes->>'data_valid' = 'false'
+		       OR attributes->>'apex_cname' = 'true')
+		ORDER BY domain, subdomain
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []RecordIssue
+	for rows.Next() {
+		var r RecordIssue
+		var dataError sql.NullString
+		var txtStrings string
+		var unflattenedApex bool
+
+		if err := rows.Scan(&r.Domain, &r.Subdomain, &r.RecordType, &r.Data, &r.Source, &dataError, &txtStrings, &unflattenedApex); err != nil {
+			return nil, err
+		}
+
+		if dataError.Valid {
+			r.Issues = append(r.Issues, dataError.String)
+		}
+		if unflattenedApex {
+			r.Issues = append(r.Issues, "CNAME at zone apex conflicts with the apex SOA/NS records and is not flattened by the provider")
+		}
+		if r.RecordType == "TXT" || r.RecordType == "SPF" {
+			r.Issues = append(r.Issues, dns.CheckTXTData(r.Data, txtStrings)...)
+		}
+
+		if len(r.Issues) > 0 {
+			results = append(results, r)
+		}
+	}
+
+	return results, rows.Err()
+}
+
+// DomainRisk describes registrar settings that put a domain at risk
+type DomainRisk struct {
+	Domain    string   `json:"domain"`
+	Registrar string   `json:"registrar"`
+	Risks     []string `json:"risks"`
+}
+
+// GetDomainRisks returns active domains with registrar lock or auto-renew off
+// Only domains whose registrar reports these flags (GoDaddy) are checked.
+func (s *SyncService) GetDomainRisks(ctx context.Context) ([]DomainRisk, error) {
+	rows, err := s.db.Reader().QueryContext(ctx, `
+		SELECT domain, registrar,
+		       COALESCE(attributes->>'locked' = 'false', FALSE),
+		       COALESCE(attributes->>'renew_auto' = 'false', FALSE)
+		FROM domains
+		WHERE status = 'active'
+		  AND (attributes->>'locked' = 'false' OR attributes->>'renew_auto' = 'false')
+		ORDER BY domain
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []DomainRisk
+	for rows.Next() {
+		var r DomainRisk
+		var unlocked, noAutoRenew bool
+
+		if err := rows.Scan(&r.Domain, &r.Registrar, &unlocked, &noAutoRenew); err != nil {
+			return nil, err
+		}
+
+		if unlocked {
+			r.Risks = append(r.Risks, "registrar lock disabled (domain can be transferred away)")
+		}
+		if noAutoRenew {
+			r.Risks = append(r.Risks, "auto-renew disabled (domain can expire)")
+		}
+
+		results = append(results, r)
+	}
+
+	return results, rows.Err()
+}
+
+// GetTakeoverCandidates returns the active CNAME records worth checking for
+// subdomain takeover (see analysis.TakeoverCandidates)
+func (s *SyncService) GetTakeoverCandidates(ctx context.Context, cfg config.AnalysisConfig) ([]analysis.CNAMERecord, error) {
+	return analysis.TakeoverCandidates(ctx, s.db, cfg)
+}
+
+// GetDanglingCNAMEs returns the takeover candidates whose target looks
+// deprovisioned (see analysis.FindDanglingCNAMEs)
+func (s *SyncService) GetDanglingCNAMEs(ctx context.Context, cfg config.AnalysisConfig) ([]analysis.DanglingCNAME, error) {
+	return analysis.FindDanglingCNAMEs(ctx, s.db, cfg)
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetTXTIssuesReportsStoredLongValue(t *testing.T) {
+	syncSvc, db := newTestSyncService(t)
+
+	source := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	t.Cleanup(func() { db.Exec(`DELETE FROM dns_records WHERE source = $1`, source) })
+
+	// Stored as collected: joined data, the provider's strings in attributes
+	long := "v=DKIM1; k=rsa; p=" + strings.Repeat("A", 282)
+	insert := `
+		INSERT INTO dns_records (domain, subdomain, record_type, data, source, attributes)
+		VALUES ('issues-test.example', $1, 'TXT', $2, $3, $4)
+	`
+	if _, err := db.Exec(insert, "unsplit._domainkey", long, source, `{"txt_strings": "300"}`); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if _, err := db.Exec(insert, "split._domainkey", long, source, `{"txt_strings": "255,45"}`); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	issues, err := syncSvc.GetTXTIssues(context.Background())
+	if err != nil {
+		t.Fatalf("GetTXTIssues: %v", err)
+	}
+
+	var reported []string
+	for _, r := range issues {
+		if r.Source == source {
+			reported = append(reported, r.Subdomain)
+		}
+	}
+	if want := []string{"unsplit._domainkey"}; fmt.Sprint(reported) != fmt.Sprint(want) {
+		t.Errorf("reported = %q, want %q", reported, want)
+	}
+}
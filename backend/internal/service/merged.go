@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"0xdomainsnapshot/internal/collector/dns"
+)
+
+// MergedRecord is the effective record set for one (subdomain, type) of a domain
+type MergedRecord struct {
+	Subdomain    string              `json:"subdomain"`
+	FQDN         string              `json:"fqdn"`
+	RecordType   string              `json:"type"`
+	Data         []string            `json:"data"`   // Values served by the winning source
+	Source       string              `json:"source"` // Highest-priority source that has this record set
+	Sources      []string            `json:"sources"`
+	Proxied      bool                `json:"proxied"`
+	Conflict     bool                `json:"conflict"`               // Another source has different values
+	Alternatives map[string][]string `json:"alternatives,omitempty"` // Differing values by source
+}
+
+// MergedView is the reconciled record set of a domain across all sources
+type MergedView struct {
+	Domain    string         `json:"domain"`
+	Records   []MergedRecord `json:"records"`
+	Conflicts int            `json:"conflicts"`
+}
+
+// GetMergedRecords returns one record set per (subdomain, type) for a domain
+// Active records are grouped by source. The source listed first in the
+// configured priority wins (unlisted sources rank after, alphabetically);
+// other sources whose values differ are reported as alternatives.
+func (s *SyncService) GetMergedRecords(ctx context.Context, domain string) (*MergedView, error) {
+	records, err := s.GetDNSRecords(ctx, DNSRecordQuery{Status: "active", Domain: domain})
+	if err != nil {
+		return nil, err
+	}
+
+	type rrset struct {
+		subdomain, recordType string
+		bySource              map[string][]string
+		proxied               map[string]bool
+	}
+
+	var order []string
+	sets := make(map[string]*rrset)
+
+	for _, r := range records {
+		subdomain, recordType, source := str(r["subdomain"]), str(r["type"]), str(r["source"])
+		key := subdomain + "\x00" + recordType
+
+		set, ok := sets[key]
+		if !ok {
+			set = &rrset{
+				subdomain:  subdomain,
+				recordType: recordType,
+				bySource:   make(map[string][]string),
+				proxied:    make(map[string]bool),
+			}
+			sets[key] = set
+			order = append(order, key)
+		}
+
+		set.bySource[source] = append(set.bySource[source], str(r["data"]))
+		if p, _ := r["proxied"].(bool); p {
+			set.proxied[source] = true
+		}
+	}
+
+	view := &MergedView{Domain: domain, Records: []MergedRecord{}}
+
+	for _, key := range order {
+		set := sets[key]
+
+		sources := make([]string, 0, len(set.bySource))
+		for source, values := range set.bySource {
+			sort.Strings(values)
+			sources = append(sources, source)
+		}
+		sort.Slice(sources, func(i, j int) bool {
+			pi, pj := s.sourceRank(sources[i]), s.sourceRank(sources[j])
+			if pi != pj {
+				return pi < pj
+			}
+			return sources[i] < sources[j]
+		})
+
+		winner := sources[0]
+		merged := MergedRecord{
+			Subdomain:  set.subdomain,
+			FQDN:       dns.FQDN(set.subdomain, domain),
+			RecordType: set.recordType,
+			Data:       set.bySource[winner],
+			Source:     winner,
+			Sources:    sources,
+			Proxied:    set.proxied[winner],
+		}
+
+		for _, source := range sources[1:] {
+			if !sameValues(set.bySource[source], merged.Data) {
+				if merged.Alternatives == nil {
+					merged.Alternatives = make(map[string][]string)
+				}
+				merged.Alternatives[source] = set.bySource[source]
+				merged.Conflict = true
+			}
+		}
+
+		if merged.Conflict {
+			view.Conflicts++
+		}
+		view.Records = append(view.Records, merged)
+	}
+
+	return view, nil
+}
+
+// sourceRank returns the position of a source in the configured priority
+func (s *SyncService) sourceRank(source string) int {
+	for i, p := range s.sourcePriority {
+		if strings.EqualFold(p, source) {
+			return i
+		}
+	}
+	return len(s.sourcePriority)
+}
+
+// sameValues compares two sorted value lists, hostnames case-insensitively
+func sameValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
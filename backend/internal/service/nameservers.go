@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"0xdomainsnapshot/internal/collector"
+)
+
+// NSChange represents a detected change of a domain's nameserver set
+type NSChange struct {
+	Domain     string    `json:"domain"`
+	Source     string    `json:"source"`
+	PreviousNS []string  `json:"previous_ns"`
+	CurrentNS  []string  `json:"current_ns"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// nameserverSets builds the apex NS set per domain from collected records
+// Returns a map of domain -> sorted, comma-separated nameservers.
+func nameserverSets(records []collector.DNSRecord) map[string]string {
+	byDomain := make(map[string][]string)
+	for _, r := range records {
+		if r.Subdomain != "" || r.RecordType != "NS" {
+			continue
+		}
+		ns := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(r.Data)), ".")
+		if ns != "" {
+			byDomain[r.Domain] = append(byDomain[r.Domain], ns)
+		}
+	}
+
+	sets := make(map[string]string, len(byDomain))
+	for domain, nameservers := range byDomain {
+		sort.Strings(nameservers)
+		sets[domain] = strings.Join(nameservers, ",")
+	}
+	return sets
+}
+
+// trackNSChanges compares collected NS sets against the stored ones
+// Records a change for every domain whose set differs from the previous sync,
+// then stores the new set. Domains without apex NS in this run are left alone.
+func (s *SyncService) trackNSChanges(ctx context.Context, source string, records []collector.DNSRecord) (int, error) {
+	sets := nameserverSets(records)
+	if len(sets) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	changes := 0
+	for domain, current := range sets {
+		var previous string
+		err := tx.QueryRowContext(ctx, `
+			SELECT nameservers FROM ns_sets
+			WHERE domain = $1 AND source = $2
+		`, domain, source).Scan(&previous)
+
+		if err != nil && err != sql.ErrNoRows {
+			return 0, fmt.Errorf("query NS set for %s: %w", domain, err)
+		}
+
+		if err == nil && previous != current {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO ns_changes (domain, source, previous_ns, current_ns)
+				VALUES ($1, $2, $3, $4)
+			`, domain, source, previous, current)
+			if err != nil {
+				return 0, fmt.Errorf("record NS change for %s: %w", domain, err)
+			}
+			slog.Info("NS change detected", "domain", domain, "source", source, "previous", previous, "current", current)
+			changes++
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO ns_sets (domain, source, nameservers, updated_at)
+			VALUES ($1, $2, $3, NOW())
+			ON CONFLICT (domain, source)
+			DO UPDATE SET nameservers = EXCLUDED.nameservers, updated_at = NOW()
+		`, domain, source, current)
+		if err != nil {
+			return 0, fmt.Errorf("store NS set for %s: %w", domain, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return changes, nil
+}
+
+// GetNSChanges retrieves nameserver changes detected within the last N days
+func (s *SyncService) GetNSChanges(ctx context.Context, days int) ([]NSChange, error) {
+	since := time.Now().AddDate(0, 0, -days)
+
+	rows, err := s.db.Reader().QueryContext(ctx, `
+		SELECT domain, source, previous_ns, current_ns, detected_at
+		FROM ns_changes
+		WHERE detected_at >= $1
+		ORDER BY detected_at DESC
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []NSChange
+	for rows.Next() {
+		var c NSChange
+		var previous, current string
+
+		if err := rows.Scan(&c.Domain, &c.Source, &previous, &current, &c.DetectedAt); err != nil {
+			return nil, err
+		}
+
+		c.PreviousNS = strings.Split(previous, ",")
+		c.CurrentNS = strings.Split(current, ",")
+		changes = append(changes, c)
+	}
+
+	return changes, rows.Err()
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// listFiles are the exported JSON arrays that can be split into parts
+var listFiles = []string{"domains.json", "subdomains.json", "removed.json"}
+
+// PartsIndex describes a list file split into parts
+// Written as <name>.index.json next to <name>.part1.json ... partN.json.
+type PartsIndex struct {
+	Total             int      `json:"total"`
+	MaxRecordsPerFile int      `json:"max_records_per_file"`
+	Parts             []string `json:"parts"`
+}
+
+// indexName returns the index file name of a list file
+func indexName(filename string) string {
+	return strings.TrimSuffix(filename, ".json") + ".index.json"
+}
+
+// partName returns the name of part n (1-based) of a list file
+func partName(filename string, n int) string {
+	return fmt.Sprintf("%s.part%d.json", strings.TrimSuffix(filename, ".json"), n)
+}
+
+// writeJSONList writes a list file to dir, split into parts when
+// maxRecordsPerFile is set. Returns the names written.
+func (e *ExportService) writeJSONList(dir, filename string, entries []map[string]interface{}) ([]string, error) {
+	if e.maxRecordsPerFile <= 0 {
+		if err := writeJSON(dir, filename, entries); err != nil {
+			return nil, err
+		}
+		return []string{filename}, nil
+	}
+
+	index := PartsIndex{Total: len(entries), MaxRecordsPerFile: e.maxRecordsPerFile, Parts: []string{}}
+	for start := 0; start < len(entries); start += e.maxRecordsPerFile {
+		end := start + e.maxRecordsPerFile
+		if end > len(entries) {
+			end = len(entries)
+		}
+
+		name := partName(filename, len(index.Parts)+1)
+		if err := writeJSON(dir, name, entries[start:end]); err != nil {
+			return nil, err
+		}
+		index.Parts = append(index.Parts, name)
+	}
+
+	if err := writeJSON(dir, indexName(filename), index); err != nil {
+		return nil, err
+	}
+	return append(index.Parts, indexName(filename)), nil
+}
+
+// readJSONList reads a published list file, joining its parts if split
+// The layout read is the one currently configured; a file published with
+// the other layout is reported as missing.
+func (e *ExportService) readJSONList(filename string) ([]map[string]interface{}, error) {
+	if e.maxRecordsPerFile <= 0 {
+		return readJSONFile(filepath.Join(e.outputDir, filename))
+	}
+
+	data, err := os.ReadFile(filepath.Join(e.outputDir, indexName(filename)))
+	if err != nil {
+		return nil, err
+	}
+	var index PartsIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, err
+	}
+
+	entries := make([]map[string]interface{}, 0, index.Total)
+	for _, part := range index.Parts {
+		partEntries, err := readJSONFile(filepath.Join(e.outputDir, filepath.Base(part)))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", part, err)
+		}
+		entries = append(entries, partEntries...)
+	}
+	return entries, nil
+}
+
+// readJSONFile reads a JSON array file
+func readJSONFile(path string) ([]map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []map[string]interface{}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
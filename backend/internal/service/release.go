@@ -0,0 +1,282 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Published exports live in versioned release directories inside the
+// output directory. The .current symlink points at the published release
+// and every published name is a symlink through it:
+//
+//	domains.json  -> .current/domains.json
+//	zones         -> .current/zones
+//	.current      -> .releases/<id>
+//
+// Publishing switches .current in one rename, so readers see either the
+// previous or the new set of files, never a mix.
+const (
+	releasesDir  = ".releases"
+	currentLink  = ".current"
+	keepReleases = 2 // The published release and the one before it
+
+	releaseIDFormat = "20060102T150405.000000000Z"
+)
+
+// partPattern matches the part files of a split list file
+var partPattern = regexp.MustCompile(`^(.+)\.part\d+\.json$`)
+
+// listOf returns the list file a published name is a variant of (the file
+// itself, its index or one of its parts), "" if none
+func listOf(name string) string {
+	for _, filename := range listFiles {
+		base := strings.TrimSuffix(filename, ".json")
+		if name == filename || name == indexName(filename) {
+			return filename
+		}
+		if m := partPattern.FindStringSubmatch(name); m != nil && m[1] == base {
+			return filename
+		}
+	}
+	return ""
+}
+
+// isExportName reports whether a name in the output directory belongs to
+// the export (other tools may keep their own files there)
+func isExportName(name string) bool {
+	return listOf(name) != "" || name == "zones" || name == "metadata.json"
+}
+
+// publish makes the staged names the published export in one step
+// The staging directory is completed with the files of the current
+// release it doesn't replace (hard links), moved to .releases/<id> and
+// .current is switched to it. If anything fails before the switch, the
+// previous set stays published. Published files are hashed right away so
+// their ETags are ready for the next request.
+// Callers hold e.mu: a concurrent publish could prune the release this one
+// carries files over from.
+func (e *ExportService) publish(staging string, names []string) error {
+	if err := e.carryOver(staging, names); err != nil {
+		return fmt.Errorf("carry over unchanged files: %w", err)
+	}
+
+	releases := filepath.Join(e.outputDir, releasesDir)
+	if err := os.MkdirAll(releases, 0755); err != nil {
+		return fmt.Errorf("create releases directory: %w", err)
+	}
+
+	// MkdirTemp creates the staging directory private to us
+	if err := os.Chmod(staging, 0755); err != nil {
+		return fmt.Errorf("open up staging directory: %w", err)
+	}
+
+	id := time.Now().UTC().Format(releaseIDFormat)
+	release := filepath.Join(releases, id)
+	if err := os.Rename(staging, release); err != nil {
+		return fmt.Errorf("move staging directory to release %s: %w", id, err)
+	}
+
+	if err := e.switchCurrent(id); err != nil {
+		os.RemoveAll(release)
+		return fmt.Errorf("switch to release %s: %w", id, err)
+	}
+
+	entries, err := os.ReadDir(release)
+	if err != nil {
+		return fmt.Errorf("read release %s: %w", id, err)
+	}
+	for _, entry := range entries {
+		if err := e.linkPublished(id, entry.Name()); err != nil {
+			return fmt.Errorf("link %s: %w", entry.Name(), err)
+		}
+		if _, err := e.etags.get(filepath.Join(e.outputDir, entry.Name())); err != nil {
+			log.Printf("[Export] Warning: hashing %s failed: %v", entry.Name(), err)
+		}
+	}
+
+	if err := e.removeStaleLinks(); err != nil {
+		log.Printf("[Export] Warning: removing stale links failed: %v", err)
+	}
+	if err := e.pruneReleases(id); err != nil {
+		log.Printf("[Export] Warning: pruning old releases failed: %v", err)
+	}
+	return nil
+}
+
+// carryOver links the published files the staged names don't replace into
+// the staging directory, so the new release is a complete set
+// Variants of a staged list file (the single file, index or parts of the
+// other layout) are not carried over. Before the first release the export
+// files are taken from the output directory itself.
+func (e *ExportService) carryOver(staging string, names []string) error {
+	staged := make(map[string]bool, len(names))
+	stagedLists := make(map[string]bool)
+	for _, name := range names {
+		staged[name] = true
+		if list := listOf(name); list != "" {
+			stagedLists[list] = true
+		}
+	}
+
+	current := filepath.Join(e.outputDir, currentLink)
+	if _, err := os.Stat(current); os.IsNotExist(err) {
+		current = e.outputDir
+	}
+
+	entries, err := os.ReadDir(current)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if staged[name] || stagedLists[listOf(name)] || !isExportName(name) {
+			continue
+		}
+		if err := linkPath(filepath.Join(current, name), filepath.Join(staging, name)); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// linkPath hard links a file, or a directory of files, to dst; files that
+// can't be linked are copied
+func linkPath(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		if err := os.MkdirAll(dst, 0755); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := linkPath(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	return copyPath(src, dst)
+}
+
+// switchCurrent points .current at a release by renaming a new symlink
+// over it
+// The temporary link is named after the release, so a publish of another
+// process on the same output directory can't remove it.
+func (e *ExportService) switchCurrent(id string) error {
+	tmp := filepath.Join(e.outputDir, currentLink+"."+id+".tmp")
+	if err := os.Symlink(filepath.Join(releasesDir, id), tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(e.outputDir, currentLink)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// linkPublished makes a published name a symlink through .current
+// Files and directories published before releases were used are replaced
+// by the link.
+func (e *ExportService) linkPublished(id, name string) error {
+	dst := filepath.Join(e.outputDir, name)
+	target := filepath.Join(currentLink, name)
+
+	if existing, err := os.Readlink(dst); err == nil && existing == target {
+		return nil
+	}
+
+	// A directory can't be renamed over; move it aside first
+	if info, err := os.Lstat(dst); err == nil && info.IsDir() {
+		aside := filepath.Join(e.outputDir, releasesDir, ".old-"+id+"-"+name)
+		os.RemoveAll(aside)
+		if err := os.Rename(dst, aside); err != nil {
+			return err
+		}
+		defer os.RemoveAll(aside)
+	}
+
+	tmp := filepath.Join(e.outputDir, ".link-"+id+"-"+name)
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// removeStaleLinks deletes links to names the published release doesn't
+// have (e.g. parts of a list that is no longer split)
+func (e *ExportService) removeStaleLinks() error {
+	entries, err := os.ReadDir(e.outputDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.Type()&os.ModeSymlink == 0 {
+			continue
+		}
+		path := filepath.Join(e.outputDir, entry.Name())
+		target, err := os.Readlink(path)
+		if err != nil || !strings.HasPrefix(target, currentLink+string(filepath.Separator)) {
+			continue
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pruneReleases deletes all but the newest keepReleases releases
+// The published release is always kept.
+func (e *ExportService) pruneReleases(current string) error {
+	releases := filepath.Join(e.outputDir, releasesDir)
+	entries, err := os.ReadDir(releases)
+	if err != nil {
+		return err
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if _, err := time.Parse(releaseIDFormat, entry.Name()); err == nil && entry.IsDir() {
+			ids = append(ids, entry.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+
+	for i, id := range ids {
+		if i < keepReleases || id == current {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(releases, id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
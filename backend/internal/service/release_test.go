@@ -0,0 +1,242 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newTestExportService(t *testing.T) *ExportService {
+	t.Helper()
+	return &ExportService{outputDir: t.TempDir(), etags: newETagCache()}
+}
+
+// stage writes files (name -> content, "zones/x.zone" for the zones
+// directory) into a new staging directory and returns their top-level names
+func stage(t *testing.T, e *ExportService, files map[string]string) (string, []string) {
+	t.Helper()
+	staging, err := os.MkdirTemp(e.outputDir, ".export-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for name, content := range files {
+		path := filepath.Join(staging, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		top, _, _ := strings.Cut(name, "/")
+		if !seen[top] {
+			seen[top] = true
+			names = append(names, top)
+		}
+	}
+	return staging, names
+}
+
+func assertFile(t *testing.T, e *ExportService, name, want string) {
+	t.Helper()
+	got, err := os.ReadFile(filepath.Join(e.outputDir, name))
+	if err != nil {
+		t.Errorf("read %s: %v", name, err)
+		return
+	}
+	if string(got) != want {
+		t.Errorf("%s = %q, want %q", name, got, want)
+	}
+}
+
+func assertMissing(t *testing.T, e *ExportService, name string) {
+	t.Helper()
+	if _, err := os.Lstat(filepath.Join(e.outputDir, name)); !os.IsNotExist(err) {
+		t.Errorf("%s still published (err %v)", name, err)
+	}
+}
+
+func TestPublishReplacesLegacyFiles(t *testing.T) {
+	e := newTestExportService(t)
+
+	// Files published before releases, and another tool's data
+	for name, content := range map[string]string{
+		"domains.json":      "old domains",
+		"subdomains.json":   "old records",
+		"zones/a.com.zone":  "old zone",
+		"aws/ec2.json":      "other tool",
+		"metadata.json":     "old metadata",
+		"removed.json":      "old removed",
+		"zones/old.io.zone": "stale zone",
+	} {
+		path := filepath.Join(e.outputDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	staging, names := stage(t, e, map[string]string{
+		"domains.json":     "new domains",
+		"zones/a.com.zone": "new zone",
+		"metadata.json":    "new metadata",
+	})
+	if err := e.publish(staging, names); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	assertFile(t, e, "domains.json", "new domains")
+	assertFile(t, e, "zones/a.com.zone", "new zone")
+	assertMissing(t, e, "zones/old.io.zone")
+	assertFile(t, e, "metadata.json", "new metadata")
+	assertFile(t, e, "subdomains.json", "old records") // Carried over
+	assertFile(t, e, "removed.json", "old removed")
+	assertFile(t, e, "aws/ec2.json", "other tool") // Not ours, left alone
+
+	if target, err := os.Readlink(filepath.Join(e.outputDir, "domains.json")); err != nil || target != filepath.Join(currentLink, "domains.json") {
+		t.Errorf("domains.json link = %q, %v", target, err)
+	}
+}
+
+func TestPublishSwitchesLayout(t *testing.T) {
+	e := newTestExportService(t)
+
+	staging, names := stage(t, e, map[string]string{
+		"subdomains.part1.json": "part 1",
+		"subdomains.part2.json": "part 2",
+		"subdomains.index.json": "index",
+		"domains.json":          "domains v1",
+		"metadata.json":         "metadata v1",
+	})
+	if err := e.publish(staging, names); err != nil {
+		t.Fatalf("publish v1: %v", err)
+	}
+
+	// Records no longer split; domains untouched
+	staging, names = stage(t, e, map[string]string{
+		"subdomains.json": "records v2",
+		"metadata.json":   "metadata v2",
+	})
+	if err := e.publish(staging, names); err != nil {
+		t.Fatalf("publish v2: %v", err)
+	}
+
+	assertFile(t, e, "subdomains.json", "records v2")
+	assertFile(t, e, "domains.json", "domains v1")
+	assertFile(t, e, "metadata.json", "metadata v2")
+	for _, name := range []string{"subdomains.part1.json", "subdomains.part2.json", "subdomains.index.json"} {
+		assertMissing(t, e, name)
+	}
+
+	// Only the newest releases are kept
+	for i := 0; i < 3; i++ {
+		staging, names = stage(t, e, map[string]string{"metadata.json": "metadata"})
+		if err := e.publish(staging, names); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+	releases, err := os.ReadDir(filepath.Join(e.outputDir, releasesDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(releases) != keepReleases {
+		t.Errorf("%d releases kept, want %d", len(releases), keepReleases)
+	}
+	assertFile(t, e, "domains.json", "domains v1")
+}
+
+func TestPublishFailureKeepsPreviousSet(t *testing.T) {
+	e := newTestExportService(t)
+
+	staging, names := stage(t, e, map[string]string{
+		"domains.json":  "domains v1",
+		"metadata.json": "metadata v1",
+	})
+	if err := e.publish(staging, names); err != nil {
+		t.Fatalf("publish v1: %v", err)
+	}
+
+	// A staging directory that is gone fails before the switch
+	staging, names = stage(t, e, map[string]string{"domains.json": "domains v2"})
+	os.RemoveAll(staging)
+	if err := e.publish(staging, names); err == nil {
+		t.Fatal("publish of a missing staging directory succeeded")
+	}
+
+	assertFile(t, e, "domains.json", "domains v1")
+	assertFile(t, e, "metadata.json", "metadata v1")
+}
+
+func TestListOf(t *testing.T) {
+	tests := map[string]string{
+		"subdomains.json":        "subdomains.json",
+		"subdomains.index.json":  "subdomains.json",
+		"subdomains.part12.json": "subdomains.json",
+		"domains.part1.json":     "domains.json",
+		"metadata.json":          "",
+		"zones":                  "",
+		"subdomainsx.part1.json": "",
+	}
+	for name, want := range tests {
+		if got := listOf(name); got != want {
+			t.Errorf("listOf(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestConcurrentPublishes(t *testing.T) {
+	e := newTestExportService(t)
+
+	const n = 8
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entries := []map[string]interface{}{{"domain": fmt.Sprintf("d%d.example", i)}}
+			errs[i] = e.publishList("domains.json", entries)
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("publish %d: %v", i, err)
+		}
+	}
+
+	domains, err := e.readJSONList("domains.json")
+	if err != nil {
+		t.Fatalf("read published domains.json: %v", err)
+	}
+	if len(domains) != 1 {
+		t.Errorf("domains.json has %d entries, want 1", len(domains))
+	}
+
+	entries, err := os.ReadDir(e.outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") || strings.HasPrefix(entry.Name(), ".link-") {
+			t.Errorf("temporary link %s left behind", entry.Name())
+		}
+	}
+}
+
+func TestSwitchCurrentIgnoresStaleTempLink(t *testing.T) {
+	e := newTestExportService(t)
+
+	// Left behind by a publish that crashed between symlink and rename
+	if err := os.Symlink("nowhere", filepath.Join(e.outputDir, currentLink+".tmp")); err != nil {
+		t.Fatal(err)
+	}
+
+	staging, names := stage(t, e, map[string]string{"domains.json": "[]"})
+	if err := e.publish(staging, names); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	assertFile(t, e, "domains.json", "[]")
+}
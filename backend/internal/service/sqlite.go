@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteTable describes a table copied into the SQLite snapshot
+// Columns are selected from Postgres as text and stored as-is, except
+// integer columns which SQLite converts via column affinity.
+type sqliteTable struct {
+	name    string
+	schema  string
+	columns []string
+}
+
+// sqliteTables lists the tables included in the SQLite snapshot
+var sqliteTables = []sqliteTable{
+	{
+		name: "domains",
+		schema: `CREATE TABLE domains (
+			id              TEXT PRIMARY KEY,
+			domain          TEXT NOT NULL,
+			registrar       TEXT NOT NULL,
+			status          TEXT NOT NULL,
+			expiry_date     TEXT,
+			discovery_date  TEXT NOT NULL,
+			last_seen       TEXT NOT NULL,
+			last_present_at TEXT,
+			raw_data        TEXT,
+			attributes      TEXT,
+			created_at      TEXT,
+			updated_at      TEXT
+		)`,
+		columns: []string{"id", "domain", "registrar", "status", "expiry_date",
+			"discovery_date", "last_seen", "last_present_at", "raw_data", "attributes", "created_at", "updated_at"},
+	},
+	{
+		name: "dns_records",
+		schema: `CREATE TABLE dns_records (
+			id              TEXT PRIMARY KEY,
+			domain          TEXT NOT NULL,
+			subdomain       TEXT NOT NULL,
+			record_type     TEXT NOT NULL,
+			data            TEXT NOT NULL,
+			ttl             INTEGER,
+			priority        INTEGER,
+			proxied         TEXT NOT NULL,
+			source          TEXT NOT NULL,
+			status          TEXT NOT NULL,
+			discovery_date  TEXT NOT NULL,
+			last_seen       TEXT NOT NULL,
+			last_present_at TEXT,
+			raw_data        TEXT,
+			attributes      TEXT,
+			created_at      TEXT,
+			updated_at      TEXT
+		)`,
+		columns: []string{"id", "domain", "subdomain", "record_type", "data", "ttl", "priority", "proxied",
+			"source", "status", "discovery_date", "last_seen", "last_present_at", "raw_data", "attributes", "created_at", "updated_at"},
+	},
+	{
+		name: "sync_status",
+		schema: `CREATE TABLE sync_status (
+			id              TEXT PRIMARY KEY,
+			collector_name  TEXT NOT NULL,
+			service_type    TEXT NOT NULL,
+			status          TEXT NOT NULL,
+			started_at      TEXT NOT NULL,
+			completed_at    TEXT,
+			records_found   INTEGER,
+			records_added   INTEGER,
+			records_updated INTEGER,
+			records_removed INTEGER,
+			error_message   TEXT,
+			trigger_type    TEXT NOT NULL
+		)`,
+		columns: []string{"id", "collector_name", "service_type", "status", "started_at", "completed_at",
+			"records_found", "records_added", "records_updated", "records_removed", "error_message", "trigger_type"},
+	},
+}
+
+// sqliteIndexes are created after the data is loaded
+var sqliteIndexes = []string{
+	"CREATE INDEX idx_domains_domain ON domains(domain)",
+	"CREATE INDEX idx_dns_records_domain ON dns_records(domain)",
+	"CREATE INDEX idx_dns_records_type ON dns_records(record_type)",
+	"CREATE INDEX idx_sync_status_collector ON sync_status(collector_name)",
+}
+
+// ExportSQLite writes domains, dns_records and sync_status into a new
+// self-contained SQLite file at path. Any existing file is replaced.
+func (e *ExportService) ExportSQLite(ctx context.Context, path string) error {
+	log.Printf("[Export] Exporting SQLite snapshot to %s", path)
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove existing file: %w", err)
+	}
+
+	lite, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return fmt.Errorf("open sqlite: %w", err)
+	}
+	defer lite.Close()
+
+	tx, err := lite.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin sqlite transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, t := range sqliteTables {
+		if _, err := tx.ExecContext(ctx, t.schema); err != nil {
+			return fmt.Errorf("create table %s: %w", t.name, err)
+		}
+
+		count, err := e.copyTable(ctx, tx, t)
+		if err != nil {
+			return fmt.Errorf("copy table %s: %w", t.name, err)
+		}
+		log.Printf("[Export] SQLite: copied %d rows into %s", count, t.name)
+	}
+
+	for _, idx := range sqliteIndexes {
+		if _, err := tx.ExecContext(ctx, idx); err != nil {
+			return fmt.Errorf("create index: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit sqlite transaction: %w", err)
+	}
+
+	log.Printf("[Export] SQLite snapshot complete")
+	return nil
+}
+
+// copyTable streams all rows of a table from Postgres into SQLite
+// Reads the primary so a snapshot taken right after a merge includes it.
+func (e *ExportService) copyTable(ctx context.Context, tx *sql.Tx, t sqliteTable) (int, error) {
+	selectCols := make([]string, len(t.columns))
+	placeholders := make([]string, len(t.columns))
+	for i, col := range t.columns {
+		selectCols[i] = col + "::text"
+		placeholders[i] = "?"
+	}
+
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		t.name, strings.Join(t.columns, ", "), strings.Join(placeholders, ", ")))
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	rows, err := e.syncSvc.db.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s",
+		strings.Join(selectCols, ", "), t.name))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	values := make([]sql.NullString, len(t.columns))
+	args := make([]interface{}, len(t.columns))
+	scanArgs := make([]interface{}, len(t.columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return count, err
+		}
+
+		for i, v := range values {
+			if v.Valid {
+				args[i] = v.String
+			} else {
+				args[i] = nil
+			}
+		}
+
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, rows.Err()
+}
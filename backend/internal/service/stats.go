@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+)
+
+// AssetCounts holds the inventory counts shown on the dashboard and in metadata
+// Counts are deduplicated: a domain is counted once by name, a DNS record
+// once by (domain, subdomain, type, data), however many sources report it.
+// - Active: seen as active by at least one source
+// - Removed: no longer active at any source
+// - Total: Active + Removed
+type AssetCounts struct {
+	Active  int `json:"active"`
+	Removed int `json:"removed"`
+	Total   int `json:"total"`
+}
+
+// countQueries holds the deduplicated count query per asset table
+var countQueries = map[string]string{
+	"domains": `
+		SELECT COUNT(*) FILTER (WHERE active), COUNT(*) FILTER (WHERE NOT active)
+		FROM (
+			SELECT bool_or(status = 'active') AS active
+			FROM domains
+			GROUP BY domain
+		) d
+	`,
+	"dns_records": `
+		SELECT COUNT(*) FILTER (WHERE active), COUNT(*) FILTER (WHERE NOT active)
+		FROM (
+			SELECT bool_or(status = 'active') AS active
+			FROM dns_records
+			GROUP BY domain, subdomain, record_type, data
+		) r
+	`,
+}
+
+// GetAssetCounts returns the deduplicated domain and DNS record counts
+func (s *SyncService) GetAssetCounts(ctx context.Context) (domains, records AssetCounts, err error) {
+	return s.assetCounts(ctx, false)
+}
+
+// assetCounts is GetAssetCounts, reading the primary when primary is set
+func (s *SyncService) assetCounts(ctx context.Context, primary bool) (domains, records AssetCounts, err error) {
+	if domains, err = s.countAssets(ctx, "domains", primary); err != nil {
+		return domains, records, fmt.Errorf("count domains: %w", err)
+	}
+	if records, err = s.countAssets(ctx, "dns_records", primary); err != nil {
+		return domains, records, fmt.Errorf("count DNS records: %w", err)
+	}
+	return domains, records, nil
+}
+
+// countAssets runs the count query for one asset table
+func (s *SyncService) countAssets(ctx context.Context, table string, primary bool) (AssetCounts, error) {
+	var c AssetCounts
+	if err := s.reader(primary).QueryRowContext(ctx, countQueries[table]).Scan(&c.Active, &c.Removed); err != nil {
+		return c, err
+	}
+	c.Total = c.Active + c.Removed
+	return c, nil
+}
+
+// Stats summarizes the inventory without loading it
+// Totals are deduplicated as in AssetCounts. Per-source counts are per
+// source's own rows (a record at two providers counts once for each);
+// per-type counts are deduplicated across sources.
+type Stats struct {
+	Domains            AssetCounts            `json:"domains"`
+	DNSRecords         AssetCounts            `json:"dns_records"`
+	DomainsBySource    map[string]AssetCounts `json:"domains_by_source"`
+	DNSRecordsBySource map[string]AssetCounts `json:"dns_records_by_source"`
+	DNSRecordsByType   map[string]AssetCounts `json:"dns_records_by_type"`
+}
+
+// groupedCountQueries return (group, active, removed) rows
+var groupedCountQueries = map[string]string{
+	"domains_by_source": `
+		SELECT registrar, COUNT(*) FILTER (WHERE status = 'active'), COUNT(*) FILTER (WHERE status <> 'active')
+		FROM domains
+		GROUP BY registrar
+	`,
+	"dns_records_by_source": `
+		SELECT source, COUNT(*) FILTER (WHERE status = 'active'), COUNT(*) FILTER (WHERE status <> 'active')
+		FROM dns_records
+		GROUP BY source
+	`,
+	"dns_records_by_type": `
+		SELECT record_type, COUNT(*) FILTER (WHERE active), COUNT(*) FILTER (WHERE NOT active)
+		FROM (
+			SELECT record_type, bool_or(status = 'active') AS active
+			FROM dns_records
+			GROUP BY domain, subdomain, record_type, data
+		) r
+		GROUP BY record_type
+	`,
+}
+
+// GetStats returns total, per-source and per-type asset counts
+func (s *SyncService) GetStats(ctx context.Context) (*Stats, error) {
+	var stats Stats
+	var err error
+
+	if stats.Domains, stats.DNSRecords, err = s.GetAssetCounts(ctx); err != nil {
+		return nil, err
+	}
+	if stats.DomainsBySource, err = s.countGrouped(ctx, "domains_by_source"); err != nil {
+		return nil, fmt.Errorf("count domains by source: %w", err)
+	}
+	if stats.DNSRecordsBySource, err = s.countGrouped(ctx, "dns_records_by_source"); err != nil {
+		return nil, fmt.Errorf("count DNS records by source: %w", err)
+	}
+	if stats.DNSRecordsByType, err = s.countGrouped(ctx, "dns_records_by_type"); err != nil {
+		return nil, fmt.Errorf("count DNS records by type: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// countGrouped runs one grouped count query
+func (s *SyncService) countGrouped(ctx context.Context, name string) (map[string]AssetCounts, error) {
+	rows, err := s.db.Reader().QueryContext(ctx, groupedCountQueries[name])
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]AssetCounts)
+	for rows.Next() {
+		var group string
+		var c AssetCounts
+		if err := rows.Scan(&group, &c.Active, &c.Removed); err != nil {
+			return nil, err
+		}
+		c.Total = c.Active + c.Removed
+		counts[group] = c
+	}
+
+	return counts, rows.Err()
+}
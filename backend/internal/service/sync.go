@@ -2,10 +2,18 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 
 	"0xdomainsnapshot/internal/collector"
+	"0xdomainsnapshot/internal/collector/dns"
+	"0xdomainsnapshot/internal/config"
 	"0xdomainsnapshot/internal/database"
 	"0xdomainsnapshot/internal/merger"
 )
@@ -16,112 +24,449 @@ type SyncStats struct {
 	Added   int
 	Updated int
 	Removed int
+	Partial bool // Only part of the provider's data was collected and merged
 }
 
 // SyncService orchestrates data synchronization
 type SyncService struct {
-	db     *database.DB
-	merger *merger.Merger
+	db             *database.DB
+	merger         *merger.Merger
+	partialGrace   time.Duration
+	validation     string
+	apexCNAME      string
+	sourcePriority []string
+	snapshot       bool
+	snapshotKeep   int
+	incrMaxAge     time.Duration
+	maxDropPercent int
 }
 
 // NewSyncService creates a new SyncService
-func NewSyncService(db *database.DB) *SyncService {
+func NewSyncService(db *database.DB, cfg config.SyncConfig) *SyncService {
 	return &SyncService{
-		db:     db,
-		merger: merger.New(db),
+		db:             db,
+		merger:         merger.New(db),
+		partialGrace:   cfg.PartialMergeGrace,
+		validation:     cfg.RecordValidation,
+		apexCNAME:      cfg.ApexCNAME,
+		sourcePriority: cfg.SourcePriority,
+		snapshot:       cfg.SnapshotBeforeMerge,
+		snapshotKeep:   cfg.SnapshotKeep,
+		incrMaxAge:     cfg.IncrementalMaxAge,
+		maxDropPercent: cfg.MaxDropPercent,
 	}
 }
 
 // RunCollector runs a collector and merges the results
 func (s *SyncService) RunCollector(ctx context.Context, c collector.Collector) (*SyncStats, error) {
-	log.Printf("[Sync] Starting collector: %s", c.Name())
+	return s.RunCollectorIncremental(ctx, c, time.Time{})
+}
+
+// RunCollectorIncremental runs a collector limited to data changed since the
+// given time (typically the start of the last completed sync) and merges
+// the results. A full collection is run instead when the collector doesn't
+// implement collector.IncrementalCollector, since is zero, or since is
+// older than SYNC_INCREMENTAL_MAX_AGE.
+func (s *SyncService) RunCollectorIncremental(ctx context.Context, c collector.Collector, since time.Time) (*SyncStats, error) {
+	return s.runCollector(ctx, c, since, false)
+}
+
+// RunCollectorDryRun runs a full collection and returns what merging it
+// would change, without writing anything: the merge runs in transactions
+// that are rolled back, and no snapshot or NS change tracking is done. A
+// record drop beyond SYNC_MAX_DROP_PERCENT is reported as ErrRecordDrop
+// alongside the stats, which include the removals a confirmed run would make.
+func (s *SyncService) RunCollectorDryRun(ctx context.Context, c collector.Collector) (*SyncStats, error) {
+	return s.runCollector(ctx, c, time.Time{}, true)
+}
+
+// runCollector runs a collector and merges (or with dryRun, previews
+// merging) the results
+func (s *SyncService) runCollector(ctx context.Context, c collector.Collector, since time.Time, dryRun bool) (*SyncStats, error) {
+	slog.Info("Starting collector", "collector", c.Name(), "source", c.Source(), "dry_run", dryRun)
 
 	// Run the collector
-	result, err := c.Collect(ctx)
+	var result *collector.CollectorResult
+	var err error
+	if ic, ok := c.(collector.IncrementalCollector); ok && s.useIncremental(c, since) {
+		slog.Info("Incremental collection", "collector", c.Name(), "since", since.Format(time.RFC3339))
+		result, err = ic.CollectIncremental(ctx, since)
+	} else {
+		result, err = c.Collect(ctx)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("collector %s failed: %w", c.Name(), err)
 	}
 
+	// Check record data against its type (tag or drop invalid records)
+	if s.validation != "off" && len(result.DNSRecords) > 0 {
+		var invalid int
+		result.DNSRecords, invalid = dns.ValidateRecords(result.DNSRecords, s.validation == "strict")
+		if invalid > 0 {
+			action := "tagged"
+			if s.validation == "strict" {
+				action = "dropped"
+			}
+			slog.Warn("DNS records with data not matching their type", "collector", c.Name(), "source", c.Source(),
+				"count", invalid, "action", action)
+		}
+	}
+
+	// Tag apex/flattened CNAMEs so comparisons don't treat them as mismatches
+	if s.apexCNAME == "tag" {
+		if tagged := dns.TagFlattenedCNAMEs(result.DNSRecords); tagged > 0 {
+			slog.Info("Tagged apex/flattened CNAME records", "collector", c.Name(), "source", c.Source(), "count", tagged)
+		}
+	}
+
 	stats := &SyncStats{
 		Found: len(result.Domains) + len(result.DNSRecords),
 	}
 
+	// A cancelled run still merges what it collected, within a grace period
+	// detached from the cancelled context. Removal passes are skipped since
+	// anything not fetched would otherwise be marked removed.
+	opts := merger.MergeOptions{Account: result.Account, DryRun: dryRun}
+	if result.Partial {
+		if s.partialGrace <= 0 {
+			return stats, fmt.Errorf("collector %s interrupted, partial results discarded: %w", c.Name(), result.Error)
+		}
+
+		slog.Warn("Collector interrupted, merging partial results",
+			"collector", c.Name(), "source", c.Source(), "found", stats.Found, "error", result.Error)
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), s.partialGrace)
+		defer cancel()
+
+		stats.Partial = true
+		opts.SkipRemoval = true
+	}
+
+	// A run finding far less than the source has active usually means a
+	// broken provider response; merge it without removals until confirmed
+	var dropErr error
+	if !opts.SkipRemoval && s.maxDropPercent > 0 {
+		drop, err := s.checkDrop(ctx, c.Source(), result)
+		if err != nil {
+			return stats, fmt.Errorf("check record drop: %w", err)
+		}
+		switch {
+		case drop == "":
+		case dryRun:
+			dropErr = fmt.Errorf("%w: %s, a real run would skip removals until confirmed", ErrRecordDrop, drop)
+		case dropConfirmed(ctx):
+			slog.Info("Record drop confirmed by operator, applying removals", "collector", c.Name(), "drop", drop)
+		default:
+			slog.Warn("Record drop beyond limit, skipping removals until confirmed",
+				"collector", c.Name(), "drop", drop, "max_drop_percent", s.maxDropPercent)
+			opts.SkipRemoval = true
+			dropErr = fmt.Errorf("%w: %s, removals skipped; confirm with POST /api/v1/sync/trigger/%s?confirm_drop=true",
+				ErrRecordDrop, drop, c.Name())
+		}
+	}
+
+	// Snapshot the source's rows first so the merge can be rolled back
+	if s.snapshot && stats.Found > 0 && !dryRun {
+		if err := s.snapshotSource(ctx, c.Source()); err != nil {
+			return stats, fmt.Errorf("snapshot before merge: %w", err)
+		}
+	}
+
 	// Merge domains if any were collected
 	if len(result.Domains) > 0 {
-		log.Printf("[Sync] Merging %d domains from %s", len(result.Domains), c.Source())
-		domainStats, err := s.merger.MergeDomains(ctx, c.Source(), result.Domains)
+		slog.Info("Merging domains", "collector", c.Name(), "source", c.Source(), "found", len(result.Domains))
+		domainStats, err := s.merger.MergeDomains(ctx, c.Source(), result.Domains, opts)
 		if err != nil {
 			return stats, fmt.Errorf("merge domains: %w", err)
 		}
 		stats.Added += domainStats.Added
 		stats.Updated += domainStats.Updated
 		stats.Removed += domainStats.Removed
-		log.Printf("[Sync] Domains: added=%d updated=%d removed=%d",
-			domainStats.Added, domainStats.Updated, domainStats.Removed)
+		slog.Info("Domains merged", "collector", c.Name(), "source", c.Source(),
+			"added", domainStats.Added, "updated", domainStats.Updated, "removed", domainStats.Removed)
 	}
 
 	// Merge DNS records if any were collected
 	if len(result.DNSRecords) > 0 {
-		log.Printf("[Sync] Merging %d DNS records from %s", len(result.DNSRecords), c.Source())
-		recordStats, err := s.merger.MergeDNSRecords(ctx, c.Source(), result.DNSRecords)
+		slog.Info("Merging DNS records", "collector", c.Name(), "source", c.Source(), "found", len(result.DNSRecords))
+		recordStats, err := s.merger.MergeDNSRecords(ctx, c.Source(), result.DNSRecords, opts)
 		if err != nil {
 			return stats, fmt.Errorf("merge DNS records: %w", err)
 		}
 		stats.Added += recordStats.Added
 		stats.Updated += recordStats.Updated
 		stats.Removed += recordStats.Removed
-		log.Printf("[Sync] DNS Records: added=%d updated=%d removed=%d",
-			recordStats.Added, recordStats.Updated, recordStats.Removed)
+		slog.Info("DNS records merged", "collector", c.Name(), "source", c.Source(),
+			"added", recordStats.Added, "updated", recordStats.Updated, "removed", recordStats.Removed)
+
+		// Detect delegation changes (non-fatal, writes its history directly)
+		if !dryRun {
+			if changes, err := s.trackNSChanges(ctx, c.Source(), result.DNSRecords); err != nil {
+				slog.Warn("NS change tracking failed", "collector", c.Name(), "source", c.Source(), "error", err)
+			} else if changes > 0 {
+				slog.Info("Detected NS changes", "collector", c.Name(), "source", c.Source(), "count", changes)
+			}
+		}
 	}
 
-	log.Printf("[Sync] Collector %s complete: found=%d added=%d updated=%d removed=%d",
-		c.Name(), stats.Found, stats.Added, stats.Updated, stats.Removed)
+	slog.Info("Collector complete", "collector", c.Name(), "source", c.Source(), "dry_run", dryRun,
+		"found", stats.Found, "added", stats.Added, "updated", stats.Updated, "removed", stats.Removed)
+
+	if stats.Partial {
+		return stats, fmt.Errorf("collector %s interrupted, merged partial results: %w", c.Name(), result.Error)
+	}
+
+	return stats, dropErr
+}
 
-	return stats, nil
+// useIncremental reports whether a sync since the given time is recent
+// enough to build on; deltas over a long gap are more likely to miss changes
+func (s *SyncService) useIncremental(c collector.Collector, since time.Time) bool {
+	if since.IsZero() {
+		return false
+	}
+	if s.incrMaxAge > 0 && time.Since(since) > s.incrMaxAge {
+		slog.Info("Last completed sync too old, running full collection", "collector", c.Name(),
+			"last_sync", since.Format(time.RFC3339), "max_age", s.incrMaxAge)
+		return false
+	}
+	return true
+}
+
+// snapshotSource takes a merge snapshot of a source and prunes old ones
+func (s *SyncService) snapshotSource(ctx context.Context, source string) error {
+	id, domains, records, err := s.db.Snapshot(ctx, source)
+	if err != nil {
+		return err
+	}
+	slog.Info("Snapshot taken", "source", source, "snapshot", id, "domains", domains, "records", records)
+
+	if s.snapshotKeep > 0 {
+		if pruned, err := s.db.PruneSnapshots(ctx, source, s.snapshotKeep); err != nil {
+			slog.Warn("Pruning snapshots failed", "source", source, "error", err)
+		} else if pruned > 0 {
+			slog.Info("Pruned old snapshots", "source", source, "count", pruned)
+		}
+	}
+
+	return nil
+}
+
+// DomainQuery holds the filters for GetDomains
+type DomainQuery struct {
+	Status     string   // "active", "removed" or empty for all
+	Source     string   // Registrar, empty for all
+	Domains    []string // Restrict to these domain names, empty for all
+	IncludeRaw bool     // Include the provider's raw_data
+	Primary    bool     // Read the primary, not the replica (see SyncService.reader)
+
+	// ExpiringWithin keeps domains whose expiry date is before now+window
+	// (already expired included), ordered by soonest expiry. 0 for all.
+	ExpiringWithin time.Duration
+
+	Limit  int // Maximum number of domains, 0 for all
+	Offset int // Domains to skip (with Limit, for paging)
+}
+
+// DNSRecordQuery holds the filters for GetDNSRecords
+type DNSRecordQuery struct {
+	Status      string   // "active", "removed" or empty for all
+	Source      string   // Provider, empty for all
+	Domain      string   // Parent domain, empty for all
+	Domains     []string // Restrict to these parent domains, empty for all
+	Proxied     *bool    // Filter on the proxied flag, nil for all
+	Types       []string // Restrict to these record types (any case), empty for all
+	Pattern     string   // Shell-style glob on subdomain or hostname ("*.internal.example.com"), empty for all
+	IncludeRaw  bool     // Include the provider's raw_data
+	Consolidate bool     // Collapse identical records from different sources into one
+	Primary     bool     // Read the primary, not the replica (see SyncService.reader)
+	Limit       int      // Maximum number of records (consolidated records when consolidating), 0 for all
+	Offset      int      // Records to skip (with Limit, for paging)
+}
+
+// globToLike translates a shell-style glob into a LIKE pattern
+// "*" matches any run of characters and "?" a single one; LIKE's own
+// wildcards and the escape character are matched literally. Hostnames are
+// stored lowercase, so the pattern is lowercased too.
+func globToLike(glob string) string {
+	var b strings.Builder
+	for _, ch := range strings.ToLower(glob) {
+		switch ch {
+		case '*':
+			b.WriteByte('%')
+		case '?':
+			b.WriteByte('_')
+		case '%', '_', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(ch)
+		default:
+			b.WriteRune(ch)
+		}
+	}
+	return b.String()
+}
+
+// pageClause returns the LIMIT/OFFSET clause for a page, "" for all rows
+func pageClause(limit, offset int) string {
+	clause := ""
+	if limit > 0 {
+		clause += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	if offset > 0 {
+		clause += fmt.Sprintf(" OFFSET %d", offset)
+	}
+	return clause
+}
+
+// Ping checks that the database, and the read replica if configured, can
+// be reached
+func (s *SyncService) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("ping database: %w", err)
+	}
+	if s.db.HasReadReplica() {
+		if err := s.db.Reader().PingContext(ctx); err != nil {
+			return fmt.Errorf("ping read replica: %w", err)
+		}
+	}
+	return nil
+}
+
+// reader returns the pool for a read: the primary when asked for (reads
+// that must see the latest merge, like exports), otherwise db.Reader()
+func (s *SyncService) reader(primary bool) *sql.DB {
+	if primary {
+		return s.db.DB
+	}
+	return s.db.Reader()
+}
+
+// GetSources returns every source with domains or DNS records, sorted
+// Reads the primary: exports use it right after a merge.
+func (s *SyncService) GetSources(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT registrar FROM domains
+		UNION
+		SELECT source FROM dns_records
+		ORDER BY 1
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sources []string
+	for rows.Next() {
+		var source string
+		if err := rows.Scan(&source); err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, rows.Err()
+}
+
+// where returns the WHERE clause and arguments for the query's filters
+func (q DomainQuery) where() (string, []interface{}) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+
+	if q.Status != "" {
+		args = append(args, q.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if q.Source != "" {
+		args = append(args, q.Source)
+		where += fmt.Sprintf(" AND registrar = $%d", len(args))
+	}
+	if len(q.Domains) > 0 {
+		args = append(args, pq.Array(q.Domains))
+		where += fmt.Sprintf(" AND domain = ANY($%d)", len(args))
+	}
+	if q.ExpiringWithin > 0 {
+		args = append(args, q.ExpiringWithin.Seconds())
+		where += fmt.Sprintf(" AND expiry_date IS NOT NULL AND expiry_date <= NOW() + $%d * INTERVAL '1 second'", len(args))
+	}
+
+	return where, args
 }
 
 // GetDomains retrieves domains from the database
-func (s *SyncService) GetDomains(ctx context.Context, status, source string) ([]map[string]interface{}, error) {
+func (s *SyncService) GetDomains(ctx context.Context, q DomainQuery) ([]map[string]interface{}, error) {
+	where, args := q.where()
 	query := `
-		SELECT domain, registrar, status, expiry_date, discovery_date, last_seen
+		SELECT domain, registrar, status, expiry_date, discovery_date, last_seen, last_present_at, raw_data, attributes
 		FROM domains
-		WHERE 1=1
-	`
-	args := []interface{}{}
-	argNum := 1
+	` + where
 
-	if status != "" {
-		query += fmt.Sprintf(" AND status = $%d", argNum)
-		args = append(args, status)
-		argNum++
+	if q.ExpiringWithin > 0 {
+		query += " ORDER BY expiry_date, domain, registrar"
+	} else {
+		query += " ORDER BY domain, registrar"
 	}
-	if source != "" {
-		query += fmt.Sprintf(" AND registrar = $%d", argNum)
-		args = append(args, source)
-		argNum++
+	query += pageClause(q.Limit, q.Offset)
+
+	rows, err := s.reader(q.Primary).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
+
+	return scanDomains(rows, q.IncludeRaw)
+}
+
+// CountDomains returns the number of domains matching the query's filters
+// (Limit and Offset are ignored)
+func (s *SyncService) CountDomains(ctx context.Context, q DomainQuery) (int, error) {
+	where, args := q.where()
 
-	query += " ORDER BY domain"
+	var count int
+	err := s.db.Reader().QueryRowContext(ctx, "SELECT COUNT(*) FROM domains"+where, args...).Scan(&count)
+	return count, err
+}
+
+// GetExpiringDomains retrieves active domains expiring within the window
+// Ordered by soonest expiry; domains already past expiry come first.
+func (s *SyncService) GetExpiringDomains(ctx context.Context, within time.Duration) ([]map[string]interface{}, error) {
+	return s.GetDomains(ctx, DomainQuery{Status: "active", ExpiringWithin: within})
+}
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+// GetDomainsWithoutRecords retrieves active domains that have no active DNS records
+func (s *SyncService) GetDomainsWithoutRecords(ctx context.Context) ([]map[string]interface{}, error) {
+	rows, err := s.db.Reader().QueryContext(ctx, `
+		SELECT d.domain, d.registrar, d.status, d.expiry_date, d.discovery_date, d.last_seen, d.last_present_at, d.raw_data, d.attributes
+		FROM domains d
+		LEFT JOIN dns_records dns ON dns.domain = d.domain AND dns.status = 'active'
+		WHERE d.status = 'active' AND dns.id IS NULL
+		ORDER BY d.domain
+	`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	return scanDomains(rows, false)
+}
+
+// scanDomains converts domain rows into API/export maps
+func scanDomains(rows *sql.Rows, includeRaw bool) ([]map[string]interface{}, error) {
 	var results []map[string]interface{}
 	for rows.Next() {
 		var domain, registrar, status string
 		var expiryDate, discoveryDate, lastSeen interface{}
+		var lastPresent sql.NullTime
+		var rawData, attributes []byte
 
-		if err := rows.Scan(&domain, &registrar, &status, &expiryDate, &discoveryDate, &lastSeen); err != nil {
+		if err := rows.Scan(&domain, &registrar, &status, &expiryDate, &discoveryDate, &lastSeen, &lastPresent, &rawData, &attributes); err != nil {
 			return nil, err
 		}
 
 		result := map[string]interface{}{
-			"domain":    domain,
-			"registrar": registrar,
-			"status":    status,
+			"domain":      domain,
+			"registrar":   registrar,
+			"status":      status,
+			"fingerprint": domainFingerprint(domain, registrar),
 		}
 
 		if expiryDate != nil {
@@ -133,6 +478,15 @@ func (s *SyncService) GetDomains(ctx context.Context, status, source string) ([]
 		if lastSeen != nil {
 			result["last_seen"] = formatDate(lastSeen)
 		}
+		if lastPresent.Valid {
+			result["last_present_at"] = lastPresent.Time.UTC().Format(time.RFC3339)
+		}
+		if attributes != nil {
+			result["attributes"] = json.RawMessage(attributes)
+		}
+		if includeRaw && rawData != nil {
+			result["raw_data"] = json.RawMessage(rawData)
+		}
 
 		results = append(results, result)
 	}
@@ -140,35 +494,75 @@ func (s *SyncService) GetDomains(ctx context.Context, status, source string) ([]
 	return results, rows.Err()
 }
 
-// GetDNSRecords retrieves DNS records from the database
-func (s *SyncService) GetDNSRecords(ctx context.Context, status, source, domain string) ([]map[string]interface{}, error) {
-	query := `
-		SELECT domain, subdomain, record_type, data, source, status, discovery_date, last_seen
-		FROM dns_records
-		WHERE 1=1
-	`
+// where returns the WHERE clause and arguments for the query's filters
+func (q DNSRecordQuery) where() (string, []interface{}) {
+	where := " WHERE 1=1"
 	args := []interface{}{}
-	argNum := 1
 
-	if status != "" {
-		query += fmt.Sprintf(" AND status = $%d", argNum)
-		args = append(args, status)
-		argNum++
+	if q.Status != "" {
+		args = append(args, q.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if q.Source != "" {
+		args = append(args, q.Source)
+		where += fmt.Sprintf(" AND source = $%d", len(args))
 	}
-	if source != "" {
-		query += fmt.Sprintf(" AND source = $%d", argNum)
-		args = append(args, source)
-		argNum++
+	if q.Domain != "" {
+		args = append(args, q.Domain)
+		where += fmt.Sprintf(" AND domain = $%d", len(args))
 	}
-	if domain != "" {
-		query += fmt.Sprintf(" AND domain = $%d", argNum)
-		args = append(args, domain)
-		argNum++
+	if len(q.Domains) > 0 {
+		args = append(args, pq.Array(q.Domains))
+		where += fmt.Sprintf(" AND domain = ANY($%d)", len(args))
+	}
+	if q.Proxied != nil {
+		args = append(args, *q.Proxied)
+		where += fmt.Sprintf(" AND proxied = $%d", len(args))
+	}
+	if len(q.Types) > 0 {
+		// = ANY on the normalized types can use idx_dns_records_type
+		types := make([]string, len(q.Types))
+		for i, t := range q.Types {
+			types[i] = dns.NormalizeRecordType(t)
+		}
+		args = append(args, pq.Array(types))
+		where += fmt.Sprintf(" AND record_type = ANY($%d)", len(args))
+	}
+	if q.Pattern != "" {
+		args = append(args, globToLike(q.Pattern))
+		where += fmt.Sprintf(` AND (subdomain LIKE $%[1]d ESCAPE '\' OR
+			(CASE WHEN subdomain = '' THEN domain ELSE subdomain || '.' || domain END) LIKE $%[1]d ESCAPE '\')`, len(args))
 	}
 
-	query += " ORDER BY domain, subdomain"
+	return where, args
+}
+
+// GetDNSRecords retrieves DNS records from the database
+// When consolidating, Limit and Offset page over consolidated records: the
+// page's (domain, subdomain, type, data) keys are selected first and all
+// source copies of those keys are loaded.
+func (s *SyncService) GetDNSRecords(ctx context.Context, q DNSRecordQuery) ([]map[string]interface{}, error) {
+	where, args := q.where()
+	query := `
+		SELECT domain, subdomain, record_type, data, proxied, source, status, discovery_date, last_seen, last_present_at, raw_data, attributes
+		FROM dns_records
+	` + where
+
+	if q.Consolidate && (q.Limit > 0 || q.Offset > 0) {
+		query += `
+			AND (domain, subdomain, record_type, data) IN (
+				SELECT domain, subdomain, record_type, data
+				FROM dns_records` + where + `
+				GROUP BY domain, subdomain, record_type, data
+				ORDER BY domain, subdomain, record_type, data` + pageClause(q.Limit, q.Offset) + `
+			)`
+	}
+	query += " ORDER BY domain, subdomain, record_type, data, source"
+	if !q.Consolidate {
+		query += pageClause(q.Limit, q.Offset)
+	}
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader(q.Primary).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -177,19 +571,25 @@ func (s *SyncService) GetDNSRecords(ctx context.Context, status, source, domain
 	var results []map[string]interface{}
 	for rows.Next() {
 		var domainVal, subdomain, recType, data, source, status string
+		var proxied bool
 		var discoveryDate, lastSeen interface{}
+		var lastPresent sql.NullTime
+		var rawData, attributes []byte
 
-		if err := rows.Scan(&domainVal, &subdomain, &recType, &data, &source, &status, &discoveryDate, &lastSeen); err != nil {
+		if err := rows.Scan(&domainVal, &subdomain, &recType, &data, &proxied, &source, &status, &discoveryDate, &lastSeen, &lastPresent, &rawData, &attributes); err != nil {
 			return nil, err
 		}
 
 		result := map[string]interface{}{
-			"domain":    domainVal,
-			"subdomain": subdomain,
-			"type":      recType,
-			"data":      data,
-			"source":    source,
-			"status":    status,
+			"domain":      domainVal,
+			"subdomain":   subdomain,
+			"fqdn":        dns.FQDN(subdomain, domainVal),
+			"type":        recType,
+			"data":        data,
+			"proxied":     proxied,
+			"source":      source,
+			"status":      status,
+			"fingerprint": recordFingerprint(domainVal, subdomain, recType, source),
 		}
 
 		if discoveryDate != nil {
@@ -198,11 +598,43 @@ func (s *SyncService) GetDNSRecords(ctx context.Context, status, source, domain
 		if lastSeen != nil {
 			result["last_seen"] = formatDate(lastSeen)
 		}
+		if lastPresent.Valid {
+			result["last_present_at"] = lastPresent.Time.UTC().Format(time.RFC3339)
+		}
+		if attributes != nil {
+			result["attributes"] = json.RawMessage(attributes)
+		}
+		if q.IncludeRaw && rawData != nil {
+			result["raw_data"] = json.RawMessage(rawData)
+		}
 
 		results = append(results, result)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-	return results, rows.Err()
+	if q.Consolidate {
+		results = consolidateRecords(results)
+	}
+
+	return results, nil
+}
+
+// CountDNSRecords returns the number of DNS records matching the query's
+// filters, counting consolidated records when consolidating (Limit and
+// Offset are ignored)
+func (s *SyncService) CountDNSRecords(ctx context.Context, q DNSRecordQuery) (int, error) {
+	where, args := q.where()
+
+	query := "SELECT COUNT(*) FROM dns_records" + where
+	if q.Consolidate {
+		query = "SELECT COUNT(*) FROM (SELECT 1 FROM dns_records" + where + " GROUP BY domain, subdomain, record_type, data) r"
+	}
+
+	var count int
+	err := s.db.Reader().QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
 }
 
 // formatDate formats a date value as YYYY-MM-DD
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestDNSRecordQueryTypes(t *testing.T) {
+	tests := []struct {
+		name   string
+		q      DNSRecordQuery
+		clause string
+		types  []string
+	}{
+		{
+			name:   "single type",
+			q:      DNSRecordQuery{Types: []string{"A"}},
+			clause: "record_type = ANY($1)",
+			types:  []string{"A"},
+		},
+		{
+			name:   "several types in any case",
+			q:      DNSRecordQuery{Types: []string{"a", " Aaaa ", "cname"}},
+			clause: "record_type = ANY($1)",
+			types:  []string{"A", "AAAA", "CNAME"},
+		},
+		{
+			name:   "after other filters",
+			q:      DNSRecordQuery{Status: "active", Source: "cloudflare", Types: []string{"mx"}},
+			clause: "record_type = ANY($3)",
+			types:  []string{"MX"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := tt.q.where()
+			if !strings.Contains(where, tt.clause) {
+				t.Fatalf("where = %q, want it to contain %q", where, tt.clause)
+			}
+
+			arg, ok := args[len(args)-1].(*pq.StringArray)
+			if !ok {
+				t.Fatalf("types argument is %T, want *pq.StringArray", args[len(args)-1])
+			}
+			if got := []string(*arg); !reflect.DeepEqual(got, tt.types) {
+				t.Errorf("types = %q, want %q", got, tt.types)
+			}
+		})
+	}
+}
+
+func TestDNSRecordQueryNoTypes(t *testing.T) {
+	where, args := DNSRecordQuery{Status: "active"}.where()
+	if strings.Contains(where, "record_type") {
+		t.Errorf("where = %q, want no record_type filter", where)
+	}
+	if len(args) != 1 {
+		t.Errorf("len(args) = %d, want 1", len(args))
+	}
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	miekgdns "github.com/miekg/dns"
+
+	"0xdomainsnapshot/internal/collector/dns"
+)
+
+// zoneDefaultTTL is the $TTL for records without their own TTL
+// (Cloudflare reports "automatic" as 1, GoDaddy may report 0)
+const zoneDefaultTTL = 3600
+
+// zoneRecord is a deduplicated active record rendered into a zone file
+type zoneRecord struct {
+	subdomain  string
+	recordType string
+	data       string
+	ttl        int
+	priority   int
+}
+
+// exportZoneFiles writes one BIND zone file per domain into dir/<domain>.zone
+// Records are active records from all sources, deduplicated and grouped by
+// type, and read from the primary like the rest of the export. Lines that
+// don't parse as valid RRs are kept as comments so nothing is silently lost.
+func (e *ExportService) exportZoneFiles(ctx context.Context, dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, fmt.Errorf("create zones directory: %w", err)
+	}
+
+	rows, err := e.syncSvc.db.QueryContext(ctx, `
+		SELECT domain, subdomain, record_type, data, MAX(COALESCE(ttl, 0)), COALESCE(priority, 0)
+		FROM dns_records
+		WHERE status = 'active'
+		GROUP BY domain, subdomain, record_type, data, COALESCE(priority, 0)
+		ORDER BY domain, record_type, subdomain, data
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	zones := make(map[string][]zoneRecord)
+	var order []string
+	for rows.Next() {
+		var domain string
+		var r zoneRecord
+		if err := rows.Scan(&domain, &r.subdomain, &r.recordType, &r.data, &r.ttl, &r.priority); err != nil {
+			return 0, err
+		}
+		if _, ok := zones[domain]; !ok {
+			order = append(order, domain)
+		}
+		zones[domain] = append(zones[domain], r)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, domain := range order {
+		name := domain + ".zone"
+		if err := writeZoneFile(filepath.Join(dir, name), domain, zones[domain]); err != nil {
+			return 0, fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+
+	return len(order), nil
+}
+
+// writeZoneFile renders a domain's records as a BIND zone file
+func writeZoneFile(path, domain string, records []zoneRecord) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	origin := miekgdns.Fqdn(domain)
+
+	fmt.Fprintf(w, "; %s - exported by 0xDomainSnapshot at %s\n", domain, time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(w, "$ORIGIN %s\n", origin)
+	fmt.Fprintf(w, "$TTL %d\n", zoneDefaultTTL)
+
+	lastType := ""
+	for _, r := range records {
+		if r.recordType != lastType {
+			fmt.Fprintf(w, "\n; %s records\n", r.recordType)
+			lastType = r.recordType
+		}
+
+		line := zoneLine(domain, r)
+		if _, err := miekgdns.NewRR("$ORIGIN " + origin + "\n" + line); err != nil {
+			fmt.Fprintf(w, "; invalid: %s\n", line)
+			continue
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// zoneLine renders a record as "name [TTL] IN TYPE data"
+func zoneLine(domain string, r zoneRecord) string {
+	name := r.subdomain
+	if name == "" {
+		name = "@"
+	}
+
+	ttl := ""
+	if r.ttl > 1 {
+		ttl = fmt.Sprintf("%d ", r.ttl)
+	}
+
+	return fmt.Sprintf("%s %sIN %s %s", name, ttl, r.recordType, zoneData(domain, r))
+}
+
+// zoneData renders record data in zone-file syntax
+// - Hostnames are made absolute ("@" becomes the domain)
+// - MX and SRV get their priority prepended
+// - TXT is quoted and split into 255-char strings
+func zoneData(domain string, r zoneRecord) string {
+	switch r.recordType {
+	case "CNAME", "NS", "PTR":
+		return zoneHostname(domain, r.data)
+	case "MX":
+		return fmt.Sprintf("%d %s", r.priority, zoneHostname(domain, r.data))
+	case "SRV":
+		fields := strings.Fields(r.data)
+		if len(fields) == 0 {
+			// Rejected by the zone parser and written as "; invalid:"
+			return fmt.Sprintf("%d", r.priority)
+		}
+		fields[len(fields)-1] = zoneHostname(domain, fields[len(fields)-1])
+		return fmt.Sprintf("%d %s", r.priority, strings.Join(fields, " "))
+	case "TXT", "SPF":
+		return zoneTXT(r.data)
+	default:
+		return r.data
+	}
+}
+
+// zoneHostname returns an absolute hostname for zone-file data
+func zoneHostname(domain, host string) string {
+	if host == "@" {
+		return miekgdns.Fqdn(domain)
+	}
+	return miekgdns.Fqdn(host)
+}
+
+// zoneTXT quotes TXT data, splitting it into character-strings of at most 255 chars
+func zoneTXT(data string) string {
+	var quoted []string
+	for _, part := range dns.SplitTXTStrings(data) {
+		for len(part) > dns.MaxTXTStringLength {
+			quoted = append(quoted, quoteTXT(part[:dns.MaxTXTStringLength]))
+			part = part[dns.MaxTXTStringLength:]
+		}
+		quoted = append(quoted, quoteTXT(part))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// quoteTXT quotes a single TXT character-string
+// Backslashes and quotes are escaped, control characters are written as
+// \DDD escapes.
+func quoteTXT(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' || c == '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, "\\%03d", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"0xdomainsnapshot/internal/collector/dns"
+)
+
+func TestWriteZoneFileInvalidSRV(t *testing.T) {
+	records := []zoneRecord{
+		{subdomain: "_sip._tcp", recordType: "SRV", data: "5 5060 sip.example.com", ttl: 300, priority: 10},
+		{subdomain: "_empty._tcp", recordType: "SRV", data: "", priority: 10},
+		{subdomain: "_blank._tcp", recordType: "SRV", data: "   ", priority: 10},
+	}
+
+	path := filepath.Join(t.TempDir(), "example.com.zone")
+	if err := writeZoneFile(path, "example.com", records); err != nil {
+		t.Fatalf("writeZoneFile: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zone := string(data)
+
+	for _, want := range []string{
+		"_sip._tcp 300 IN SRV 10 5 5060 sip.example.com.\n",
+		"; invalid: _empty._tcp IN SRV 10\n",
+		"; invalid: _blank._tcp IN SRV 10\n",
+	} {
+		if !strings.Contains(zone, want) {
+			t.Errorf("zone file missing %q:\n%s", want, zone)
+		}
+	}
+}
+
+func TestZoneTXT(t *testing.T) {
+	long := strings.Repeat("a", 300)
+
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"v=spf1 -all", `"v=spf1 -all"`},
+		{long, `"` + long[:255] + `" "` + long[255:] + `"`},
+		{`say "hi" \ bye`, `"say \"hi\" \\ bye"`},
+		{"tab\there", `"tab\009here"`},
+	}
+
+	for _, tt := range tests {
+		if got := zoneTXT(tt.data); got != tt.want {
+			t.Errorf("zoneTXT(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+		if back := dns.NormalizeTXTData(tt.want); back != tt.data {
+			t.Errorf("NormalizeTXTData(%q) = %q, want %q", tt.want, back, tt.data)
+		}
+	}
+}
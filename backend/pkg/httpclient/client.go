@@ -3,15 +3,19 @@ package httpclient
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"0xdomainsnapshot/internal/config"
+	"0xdomainsnapshot/pkg/redact"
 )
 
 // Common errors
@@ -19,24 +23,56 @@ var (
 	ErrQuotaExceeded = errors.New("API quota exceeded")
 	ErrRateLimited   = errors.New("rate limited")
 	ErrNotFound      = errors.New("resource not found")
+	ErrAccessDenied  = errors.New("access denied")
 )
 
 // Client is an HTTP client with retry and rate limiting support
 type Client struct {
-	http *http.Client
-	cfg  config.RateLimitConfig
+	http    *http.Client
+	cfg     config.RateLimitConfig
+	headers http.Header // Extra headers added to every request
+	debug   bool        // Log requests and responses (credentials redacted)
 }
 
 // New creates a new HTTP client
-func New(cfg config.RateLimitConfig) *Client {
+// TLS is restricted to tlsCfg's minimum version; an invalid value falls
+// back to TLS 1.2 (config validation rejects it before this point).
+func New(cfg config.RateLimitConfig, tlsCfg config.HTTPConfig) *Client {
+	minVersion, err := tlsCfg.TLSMinVersion()
+	if err != nil {
+		minVersion = tls.VersionTLS12
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion:         minVersion,
+		InsecureSkipVerify: tlsCfg.InsecureSkipVerify,
+	}
+
 	return &Client{
 		http: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout:   60 * time.Second,
+			Transport: transport,
 		},
-		cfg: cfg,
+		cfg:   cfg,
+		debug: tlsCfg.Debug,
 	}
 }
 
+// WithHeaders sets extra headers sent with every request
+// They are applied after the per-request headers, so they can also
+// override them (e.g. a gateway that needs its own Authorization).
+func (c *Client) WithHeaders(headers map[string]string) *Client {
+	if len(headers) == 0 {
+		return c
+	}
+	c.headers = make(http.Header, len(headers))
+	for k, v := range headers {
+		c.headers.Set(k, v)
+	}
+	return c
+}
+
 // DoWithRetry performs an HTTP request with retry logic
 func (c *Client) DoWithRetry(ctx context.Context, method, url string, headers http.Header, body []byte) ([]byte, error) {
 	var lastErr error
@@ -72,6 +108,9 @@ func (c *Client) DoWithRetry(ctx context.Context, method, url string, headers ht
 		for k, v := range headers {
 			req.Header[k] = v
 		}
+		for k, v := range c.headers {
+			req.Header[k] = v
+		}
 
 		// Set default headers
 		if req.Header.Get("User-Agent") == "" {
@@ -82,11 +121,22 @@ func (c *Client) DoWithRetry(ctx context.Context, method, url string, headers ht
 		}
 
 		// Execute request
+		if c.debug {
+			log.Printf("[HTTP] -> %s %s (attempt %d) headers: %v",
+				method, redact.URL(url), attempt+1, redact.Header(req.Header))
+		}
+		start := time.Now()
 		resp, err := c.http.Do(req)
 		if err != nil {
+			if c.debug {
+				log.Printf("[HTTP] <- %s %s failed after %v: %v", method, redact.URL(url), time.Since(start), err)
+			}
 			lastErr = fmt.Errorf("request failed: %w", err)
 			continue
 		}
+		if c.debug {
+			log.Printf("[HTTP] <- %s %s %d in %v", method, redact.URL(url), resp.StatusCode, time.Since(start))
+		}
 
 		// Read response body
 		respBody, err := io.ReadAll(resp.Body)
@@ -99,11 +149,15 @@ func (c *Client) DoWithRetry(ctx context.Context, method, url string, headers ht
 		// Check for rate limiting (HTTP 429)
 		if resp.StatusCode == http.StatusTooManyRequests ||
 			strings.Contains(string(respBody), "TOO_MANY_REQUESTS") {
-			// Sleep for configured duration
+			// Sleep for as long as the server asks, or the configured duration
+			wait := c.retryAfter(resp.Header, time.Now())
+			if c.debug {
+				log.Printf("[HTTP] %s %s rate limited, retrying in %v", method, redact.URL(url), wait)
+			}
 			select {
 			case <-ctx.Done():
 				return nil, ctx.Err()
-			case <-time.After(c.cfg.SleepOn429):
+			case <-time.After(wait):
 			}
 			lastErr = ErrRateLimited
 			continue
@@ -114,6 +168,11 @@ func (c *Client) DoWithRetry(ctx context.Context, method, url string, headers ht
 			return nil, ErrQuotaExceeded
 		}
 
+		// Check for GoDaddy access denied (e.g. domain not owned by the shopper)
+		if resp.StatusCode == http.StatusForbidden && strings.Contains(string(respBody), "ACCESS_DENIED") {
+			return nil, ErrAccessDenied
+		}
+
 		// Check for 404 Not Found
 		if resp.StatusCode == http.StatusNotFound {
 			return nil, ErrNotFound
@@ -138,6 +197,34 @@ func (c *Client) DoWithRetry(ctx context.Context, method, url string, headers ht
 	return nil, fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
+// retryAfter returns how long to wait before retrying a rate limited
+// request: the response's Retry-After header (delay in seconds or an HTTP
+// date) capped at MaxRetryAfter, or SleepOn429 when the header is missing
+// or unparseable
+func (c *Client) retryAfter(header http.Header, now time.Time) time.Duration {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return c.cfg.SleepOn429
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return c.cfg.SleepOn429
+		}
+		wait = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		wait = max(at.Sub(now), 0)
+	} else {
+		return c.cfg.SleepOn429
+	}
+
+	if c.cfg.MaxRetryAfter > 0 && wait > c.cfg.MaxRetryAfter {
+		wait = c.cfg.MaxRetryAfter
+	}
+	return wait
+}
+
 // Get performs a GET request with retry logic
 func (c *Client) Get(ctx context.Context, url string, headers http.Header) ([]byte, error) {
 	return c.DoWithRetry(ctx, http.MethodGet, url, headers, nil)
@@ -163,6 +250,11 @@ func IsNotFound(err error) bool {
 	return errors.Is(err, ErrNotFound)
 }
 
+// IsAccessDenied checks if the error is an access denied error
+func IsAccessDenied(err error) bool {
+	return errors.Is(err, ErrAccessDenied)
+}
+
 // truncateString truncates a string to maxLen characters
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
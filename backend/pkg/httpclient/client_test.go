@@ -0,0 +1,83 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"0xdomainsnapshot/internal/config"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	c := New(config.RateLimitConfig{SleepOn429: 30 * time.Second, MaxRetryAfter: 5 * time.Minute}, config.HTTPConfig{})
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"missing", "", 30 * time.Second},
+		{"delta seconds", "120", 2 * time.Minute},
+		{"zero seconds", "0", 0},
+		{"padded seconds", " 7 ", 7 * time.Second},
+		{"seconds over the cap", "3600", 5 * time.Minute},
+		{"http date", now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{"http date over the cap", now.Add(time.Hour).Format(http.TimeFormat), 5 * time.Minute},
+		{"http date in the past", now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"negative seconds", "-5", 30 * time.Second},
+		{"fractional seconds", "1.5", 30 * time.Second},
+		{"garbage", "soon", 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.value != "" {
+				header.Set("Retry-After", tt.value)
+			}
+			if got := c.retryAfter(header, now); got != tt.want {
+				t.Errorf("retryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfterUncapped(t *testing.T) {
+	c := New(config.RateLimitConfig{SleepOn429: 30 * time.Second}, config.HTTPConfig{})
+
+	header := http.Header{"Retry-After": []string{"3600"}}
+	if got := c.retryAfter(header, time.Now()); got != time.Hour {
+		t.Errorf("retryAfter = %v, want %v", got, time.Hour)
+	}
+}
+
+func TestDoWithRetryHonorsRetryAfter(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	// SleepOn429 would outlast the test if the header were ignored
+	c := New(config.RateLimitConfig{SleepOn429: time.Hour, MaxRetries: 1}, config.HTTPConfig{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	body, err := c.Get(ctx, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if string(body) != `{"ok":true}` || calls != 2 {
+		t.Errorf("body = %q after %d calls, want {\"ok\":true} after 2", body, calls)
+	}
+}
@@ -0,0 +1,138 @@
+package redact
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Value replaces redacted secrets
+const Value = "[REDACTED]"
+
+// secretHeaders are always redacted, whatever their name suggests
+var secretHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// IsSecretKey reports whether a query parameter or header name looks like it
+// carries a credential (password, token, secret, key, signature, ...)
+func IsSecretKey(name string) bool {
+	k := strings.ToLower(name)
+	switch k {
+	case "sslkey", "key", "sig", "signature":
+		return true
+	}
+	for _, part := range []string{"password", "passwd", "token", "secret", "apikey", "api_key", "api-key", "auth"} {
+		if strings.Contains(k, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// URL replaces user info and secret query parameters of a URL
+// Values starting with "key=" are treated as key=value connection strings
+// (see DSN). Values that don't parse as either are redacted entirely.
+func URL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	if dsnPattern.MatchString(raw) {
+		return DSN(raw)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Value
+	}
+	if u.User != nil {
+		u.User = url.User(Value)
+	}
+
+	q := u.Query()
+	redacted := false
+	for key := range q {
+		if IsSecretKey(key) {
+			q.Set(key, Value)
+			redacted = true
+		}
+	}
+	if redacted {
+		u.RawQuery = q.Encode()
+	}
+
+	return strings.ReplaceAll(u.String(), url.QueryEscape(Value), Value)
+}
+
+// dsnPattern matches the start of a key=value connection string
+var dsnPattern = regexp.MustCompile(`^\s*[A-Za-z_]+\s*=`)
+
+// DSN replaces the secret values of a key=value connection string, as
+// accepted by libpq (host=db user=app password='s3 cret' dbname=app)
+// Strings that don't parse as one are redacted entirely.
+func DSN(raw string) string {
+	var out []string
+	s := strings.TrimSpace(raw)
+	for s != "" {
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			return Value
+		}
+		key := strings.TrimSpace(s[:eq])
+		if key == "" || strings.ContainsAny(key, " \t\n'\\") {
+			return Value
+		}
+
+		value, rest, ok := dsnValue(strings.TrimLeft(s[eq+1:], " \t\n"))
+		if !ok {
+			return Value
+		}
+		if IsSecretKey(key) {
+			value = Value
+		}
+		out = append(out, key+"="+value)
+		s = strings.TrimLeft(rest, " \t\n")
+	}
+	return strings.Join(out, " ")
+}
+
+// dsnValue splits a connection string value, single-quoted or ended by
+// whitespace (both with backslash escapes), from the rest of the string
+// The value is returned as written, quotes included.
+func dsnValue(s string) (value, rest string, ok bool) {
+	quoted := strings.HasPrefix(s, "'")
+	i := 0
+	if quoted {
+		i = 1
+	}
+	for ; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case quoted && s[i] == '\'':
+			return s[:i+1], s[i+1:], true
+		case !quoted && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n'):
+			return s[:i], s[i:], true
+		}
+	}
+	if quoted {
+		return "", "", false // Unterminated quote
+	}
+	return s, "", true
+}
+
+// Header returns a copy of h with credential-bearing values replaced
+func Header(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for k, v := range h {
+		if secretHeaders[http.CanonicalHeaderKey(k)] || IsSecretKey(k) {
+			out[k] = []string{Value}
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestURLRedactsDSNs(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{
+			name: "URL with password",
+			raw:  "postgres://app:s3cret@db:5432/snapshot?sslmode=require",
+			want: "postgres://[REDACTED]@db:5432/snapshot?sslmode=require",
+		},
+		{
+			name: "URL with secret query parameters",
+			raw:  "postgres://db/snapshot?sslmode=verify-full&sslpassword=s3cret&password=s3cret",
+			want: "postgres://db/snapshot?password=[REDACTED]&sslmode=verify-full&sslpassword=[REDACTED]",
+		},
+		{
+			name: "key=value",
+			raw:  "host=localhost user=app password=s3cret dbname=snapshot",
+			want: "host=localhost user=app password=[REDACTED] dbname=snapshot",
+		},
+		{
+			name: "key=value with quoted values and spaces around =",
+			raw:  `host = localhost password = 's3 \'cret' sslpassword=k3y sslmode=require`,
+			want: "host=localhost password=[REDACTED] sslpassword=[REDACTED] sslmode=require",
+		},
+		{
+			name: "key=value with unterminated quote",
+			raw:  "host=localhost password='s3cret",
+			want: Value,
+		},
+		{
+			name: "request URI",
+			raw:  "/api/v1/domains?token=abc&limit=10",
+			want: "/api/v1/domains?limit=10&token=[REDACTED]",
+		},
+	}
+
+	for _, tt := range tests {
+		got := URL(tt.raw)
+		if got != tt.want {
+			t.Errorf("%s: URL(%q) = %q, want %q", tt.name, tt.raw, got, tt.want)
+		}
+		if strings.Contains(got, "s3") || strings.Contains(got, "k3y") {
+			t.Errorf("%s: secret left in %q", tt.name, got)
+		}
+	}
+}
+
+func TestIsSecretKey(t *testing.T) {
+	for _, key := range []string{"password", "sslpassword", "PGPASSWORD", "passwd", "api_key", "X-Auth-Token", "key"} {
+		if !IsSecretKey(key) {
+			t.Errorf("IsSecretKey(%q) = false, want true", key)
+		}
+	}
+	for _, key := range []string{"host", "user", "dbname", "sslmode", "limit"} {
+		if IsSecretKey(key) {
+			t.Errorf("IsSecretKey(%q) = true, want false", key)
+		}
+	}
+}